@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// argSpec declares one positional argument of a command. Arguments are
+// lowercased, since PokeAPI names are, unless they are case sensitive, such
+// as file paths.
+type argSpec struct {
+	name          string
+	optional      bool
+	caseSensitive bool
+}
+
+// flagSpec declares a --flag of a command. Flags with a value placeholder
+// take a value, as --name=value or --name value; flags without one are
+// booleans.
+type flagSpec struct {
+	name  string
+	value string
+}
+
+// commandArgs is what a command callback receives after its input has been
+// checked against the command's declared arguments and flags.
+type commandArgs struct {
+	positional []string
+	flags      map[string]string
+}
+
+// arg returns the i-th positional argument, or "" if it was not given.
+func (a commandArgs) arg(i int) string {
+	if i < len(a.positional) {
+		return a.positional[i]
+	}
+	return ""
+}
+
+// flag returns the value of the named flag, or fallback if it was not given.
+func (a commandArgs) flag(name, fallback string) string {
+	if v, ok := a.flags[name]; ok {
+		return v
+	}
+	return fallback
+}
+
+func newArgs(positional ...string) commandArgs {
+	return commandArgs{positional: positional, flags: map[string]string{}}
+}
+
+func (c cliCommand) usage() string {
+	parts := []string{c.name}
+	for _, arg := range c.args {
+		if arg.optional {
+			parts = append(parts, fmt.Sprintf("[<%s>]", arg.name))
+		} else {
+			parts = append(parts, fmt.Sprintf("<%s>", arg.name))
+		}
+	}
+	for _, flag := range c.flags {
+		if flag.value == "" {
+			parts = append(parts, fmt.Sprintf("[--%s]", flag.name))
+		} else {
+			parts = append(parts, fmt.Sprintf("[--%s=<%s>]", flag.name, flag.value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c cliCommand) usageError() error {
+	return fmt.Errorf("Usage: %s", c.usage())
+}
+
+func (c cliCommand) lookupFlag(name string) (flagSpec, bool) {
+	for _, flag := range c.flags {
+		if flag.name == name {
+			return flag, true
+		}
+	}
+	return flagSpec{}, false
+}
+
+// parseArgs checks words against the command's declared arguments and flags.
+// Flags and their values are lowercased.
+func (c cliCommand) parseArgs(words []string) (commandArgs, error) {
+	args := newArgs()
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "--") {
+			if n := len(args.positional); n >= len(c.args) || !c.args[n].caseSensitive {
+				word = strings.ToLower(word)
+			}
+			args.positional = append(args.positional, word)
+			continue
+		}
+		word = strings.ToLower(word)
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(word, "--"), "=")
+		flag, ok := c.lookupFlag(name)
+		if !ok {
+			return commandArgs{}, fmt.Errorf("Unknown flag --%s. %v", name, c.usageError())
+		}
+		switch {
+		case flag.value == "" && hasValue:
+			return commandArgs{}, fmt.Errorf("Flag --%s takes no value. %v", name, c.usageError())
+		case flag.value != "" && !hasValue:
+			if i+1 == len(words) {
+				return commandArgs{}, fmt.Errorf("Flag --%s needs a value. %v", name, c.usageError())
+			}
+			i++
+			value = strings.ToLower(words[i])
+		}
+		args.flags[name] = value
+	}
+
+	required := 0
+	for _, arg := range c.args {
+		if !arg.optional {
+			required++
+		}
+	}
+	if len(args.positional) < required || len(args.positional) > len(c.args) {
+		return commandArgs{}, c.usageError()
+	}
+	return args, nil
+}
+
+// runCommand parses words for command and runs it.
+func runCommand(ctx context.Context, cfg *Config, command cliCommand, words []string) error {
+	args, err := command.parseArgs(words)
+	if err != nil {
+		return err
+	}
+	return command.callback(ctx, cfg, args)
+}
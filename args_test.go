@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	command := cliCommand{
+		name:  "catch",
+		args:  []argSpec{{name: "pokemon"}, {name: "nickname", optional: true}},
+		flags: []flagSpec{{name: "ball", value: "ball"}, {name: "quiet"}},
+	}
+	cases := []struct {
+		input      []string
+		positional []string
+		flags      map[string]string
+		wantErr    bool
+	}{
+		{
+			input:      []string{"pikachu"},
+			positional: []string{"pikachu"},
+			flags:      map[string]string{},
+		},
+		{
+			input:      []string{"pikachu", "sparky", "--ball=great-ball"},
+			positional: []string{"pikachu", "sparky"},
+			flags:      map[string]string{"ball": "great-ball"},
+		},
+		{
+			input:      []string{"--ball", "ultra-ball", "--quiet", "pikachu"},
+			positional: []string{"pikachu"},
+			flags:      map[string]string{"ball": "ultra-ball", "quiet": ""},
+		},
+		{
+			input:      []string{"Pikachu", "--Ball=Great-Ball"},
+			positional: []string{"pikachu"},
+			flags:      map[string]string{"ball": "great-ball"},
+		},
+		{input: []string{}, wantErr: true},
+		{input: []string{"pikachu", "sparky", "extra"}, wantErr: true},
+		{input: []string{"pikachu", "--ball"}, wantErr: true},
+		{input: []string{"pikachu", "--quiet=yes"}, wantErr: true},
+		{input: []string{"pikachu", "--unknown"}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		args, err := command.parseArgs(c.input)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%v: expected a usage error", c.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", c.input, err)
+			continue
+		}
+		if len(args.positional) != len(c.positional) {
+			t.Errorf("%v: expected positional %v, got %v", c.input, c.positional, args.positional)
+			continue
+		}
+		for i := range c.positional {
+			if args.positional[i] != c.positional[i] {
+				t.Errorf("%v: expected positional %v, got %v", c.input, c.positional, args.positional)
+			}
+		}
+		for name, value := range c.flags {
+			if args.flag(name, "<unset>") != value {
+				t.Errorf("%v: expected --%s=%s, got %s", c.input, name, value, args.flag(name, "<unset>"))
+			}
+		}
+	}
+}
+
+func TestParseArgsKeepsCase(t *testing.T) {
+	args, err := commands["save"].parseArgs([]string{"/tmp/Saves/Backup.json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path := args.arg(0); path != "/tmp/Saves/Backup.json" {
+		t.Errorf("expected the file path to keep its case, got %v", path)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	expected := "catch <pokemon> [--ball=<ball>]"
+	if usage := commands["catch"].usage(); usage != expected {
+		t.Errorf("expected %q, got %q", expected, usage)
+	}
+}
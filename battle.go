@@ -0,0 +1,467 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+// Wild battles are fought between the lead party member and a wild Pokemon,
+// one move each per turn. Damage follows the formula of generation V
+// onwards, without critical hits, abilities or held items:
+//
+//	damage = ((2*level/5 + 2) * power * attack/defense / 50 + 2)
+//	         * random(85..100)% * STAB * type effectiveness
+//
+// where attack and defense are the physical or special stats depending on
+// the move, and STAB is 1.5 when the move shares a type with its user.
+// Throwing a ball during a battle passes the wild Pokemon's remaining HP to
+// the catch formula, so weakening it first makes catching it easier.
+
+const (
+	maxMoves    = 4
+	maxEV       = 252
+	maxTotalEVs = 510
+)
+
+// combatant is one side of a battle.
+type combatant struct {
+	owned *OwnedPokemon
+	entry PokedexEntry
+	stats map[string]int
+	hp    int
+	moves []pokeapi.Move
+}
+
+func newCombatant(o *OwnedPokemon, entry PokedexEntry, nature pokeapi.Nature, moves []pokeapi.Move) *combatant {
+	c := &combatant{owned: o, entry: entry, stats: make(map[string]int), moves: moves}
+	for _, line := range computeStats(entry, o, nature) {
+		c.stats[line.name] = line.value
+	}
+	c.hp = c.stats["hp"]
+	for _, m := range moves {
+		if m.Power != nil {
+			return c
+		}
+	}
+	c.moves = append(c.moves, struggleMove())
+	return c
+}
+
+func (c *combatant) name() string {
+	return valueOr(c.owned.Nickname, c.owned.Pokemon)
+}
+
+func (c *combatant) fainted() bool {
+	return c.hp <= 0
+}
+
+func (c *combatant) status() string {
+	return fmt.Sprintf("%v Lv. %d: HP %d/%d", c.name(), c.owned.Level, c.hp, c.stats["hp"])
+}
+
+func (c *combatant) move(name string) (pokeapi.Move, bool) {
+	for _, m := range c.moves {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return pokeapi.Move{}, false
+}
+
+func (c *combatant) moveNames() []string {
+	names := make([]string, 0, len(c.moves))
+	for _, m := range c.moves {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// struggleMove is used by Pokemon that know no damaging move. It has no
+// type, so it hits everything normally.
+func struggleMove() pokeapi.Move {
+	power := 50
+	m := pokeapi.Move{Name: "struggle", Power: &power}
+	m.DamageClass.Name = "physical"
+	return m
+}
+
+// knownMoves returns the moves a Pokemon of level knows: like in the games,
+// the last four it learned by leveling up.
+func knownMoves(pkm pokeapi.Pokemon, level int) []string {
+	type learned struct {
+		name  string
+		level int
+	}
+	var moves []learned
+	for _, m := range pkm.Moves {
+		at := -1
+		for _, detail := range m.VersionGroupDetails {
+			if detail.MoveLearnMethod.Name == "level-up" && (at < 0 || detail.LevelLearnedAt < at) {
+				at = detail.LevelLearnedAt
+			}
+		}
+		if at >= 0 && at <= level {
+			moves = append(moves, learned{name: m.Move.Name, level: at})
+		}
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].level != moves[j].level {
+			return moves[i].level < moves[j].level
+		}
+		return moves[i].name < moves[j].name
+	})
+	if len(moves) > maxMoves {
+		moves = moves[len(moves)-maxMoves:]
+	}
+	names := make([]string, 0, len(moves))
+	for _, m := range moves {
+		names = append(names, m.name)
+	}
+	return names
+}
+
+// baseDamage is the damage formula before the random factor, STAB and type
+// effectiveness.
+func baseDamage(level, power, attack, defense int) int {
+	if defense < 1 {
+		defense = 1
+	}
+	return (2*level/5+2)*power*attack/defense/50 + 2
+}
+
+// battle is a wild battle in progress.
+type battle struct {
+	player, wild *combatant
+	captureRate  int
+	chart        *typeChart
+}
+
+// damage works out how much move hurts defender, and the type effectiveness
+// multiplier that went into it.
+func (b *battle) damage(rng *rand.Rand, attacker, defender *combatant, move pokeapi.Move) (int, float64) {
+	if move.Power == nil {
+		return 0, 1
+	}
+	attack, defense := attacker.stats["attack"], defender.stats["defense"]
+	if move.DamageClass.Name == "special" {
+		attack, defense = attacker.stats["special-attack"], defender.stats["special-defense"]
+	}
+	dmg := baseDamage(attacker.owned.Level, *move.Power, attack, defense)
+	dmg = dmg * (85 + rng.Intn(16)) / 100
+	for _, t := range attacker.entry.Types {
+		if t == move.Type.Name {
+			dmg = dmg * 3 / 2
+		}
+	}
+	multiplier := b.chart.multiplier(move.Type.Name, defender.entry.Types)
+	dmg = int(float64(dmg) * multiplier)
+	if multiplier > 0 && dmg < 1 {
+		dmg = 1
+	}
+	return dmg, multiplier
+}
+
+// attack has attacker use move on defender and describes what happened.
+func (b *battle) attack(rng *rand.Rand, attacker, defender *combatant, move pokeapi.Move) []string {
+	lines := []string{fmt.Sprintf("%v used %v!", attacker.name(), move.Name)}
+	if move.Accuracy != nil && rng.Intn(100) >= *move.Accuracy {
+		return append(lines, fmt.Sprintf("%v's attack missed!", attacker.name()))
+	}
+	if move.Power == nil {
+		return append(lines, "But nothing happened!")
+	}
+	dmg, multiplier := b.damage(rng, attacker, defender, move)
+	switch {
+	case multiplier == 0:
+		return append(lines, fmt.Sprintf("It doesn't affect %v...", defender.name()))
+	case multiplier > 1:
+		lines = append(lines, "It's super effective!")
+	case multiplier < 1:
+		lines = append(lines, "It's not very effective...")
+	}
+	defender.hp -= dmg
+	if defender.hp < 0 {
+		defender.hp = 0
+	}
+	if defender.fainted() {
+		lines = append(lines, fmt.Sprintf("%v fainted!", defender.name()))
+	}
+	return lines
+}
+
+// round plays one turn: the player's move and a random move of the wild
+// Pokemon. Higher priority moves go first, then the faster Pokemon, and a
+// Pokemon that faints does not get to move.
+func (b *battle) round(rng *rand.Rand, move pokeapi.Move) []string {
+	wildMove := b.wild.moves[rng.Intn(len(b.wild.moves))]
+	playerFirst := move.Priority > wildMove.Priority
+	if move.Priority == wildMove.Priority {
+		playerSpeed, wildSpeed := b.player.stats["speed"], b.wild.stats["speed"]
+		playerFirst = playerSpeed > wildSpeed || (playerSpeed == wildSpeed && rng.Intn(2) == 0)
+	}
+	if playerFirst {
+		lines := b.attack(rng, b.player, b.wild, move)
+		if b.wild.fainted() {
+			return lines
+		}
+		return append(lines, b.attack(rng, b.wild, b.player, wildMove)...)
+	}
+	lines := b.attack(rng, b.wild, b.player, wildMove)
+	if b.player.fainted() {
+		return lines
+	}
+	return append(lines, b.attack(rng, b.player, b.wild, move)...)
+}
+
+// wildTurn lets the wild Pokemon act on its own, as after a failed catch.
+func (b *battle) wildTurn(rng *rand.Rand) []string {
+	return b.attack(rng, b.wild, b.player, b.wild.moves[rng.Intn(len(b.wild.moves))])
+}
+
+func (b *battle) over() bool {
+	return b.player.fainted() || b.wild.fainted()
+}
+
+// awardEVs gives o the effort values of a defeated Pokemon, within the
+// games' limits of 252 per stat and 510 in total.
+func awardEVs(o *OwnedPokemon, defeated PokedexEntry) {
+	if o.EVs == nil {
+		o.EVs = make(map[string]int)
+	}
+	total := 0
+	for _, ev := range o.EVs {
+		total += ev
+	}
+	for _, s := range defeated.Stats {
+		gain := min(s.Effort, maxEV-o.EVs[s.Name], maxTotalEVs-total)
+		if gain > 0 {
+			o.EVs[s.Name] += gain
+			total += gain
+		}
+	}
+}
+
+func printLines(lines []string) {
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+func (b *battle) printStatus() {
+	fmt.Printf("   - %v\n", b.wild.status())
+	fmt.Printf("   - %v\n", b.player.status())
+	fmt.Printf("What will %v do? fight <%s>, catch %v or run\n", b.player.name(), strings.Join(b.player.moveNames(), "|"), b.wild.owned.Pokemon)
+}
+
+// finish ends the battle once either side has fainted. The lead party
+// member gains effort values for a win. cfg.mu must be held.
+func finish(cfg *Config, b *battle) {
+	switch {
+	case b.wild.fainted():
+		awardEVs(b.player.owned, b.wild.entry)
+		fmt.Printf("%v won the battle!\n", b.player.name())
+		autosave(cfg)
+	case b.player.fainted():
+		fmt.Println("You hurried back to safety!")
+	default:
+		b.printStatus()
+		return
+	}
+	cfg.Battle = nil
+}
+
+// loadMoves fetches the moves named.
+func loadMoves(ctx context.Context, client *pokeapi.Client, names []string) ([]pokeapi.Move, error) {
+	moves := make([]pokeapi.Move, 0, len(names))
+	for _, name := range names {
+		move, err := client.GetMove(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+func loadNature(ctx context.Context, client *pokeapi.Client, name string) (pokeapi.Nature, error) {
+	if name == "" {
+		return pokeapi.Nature{}, nil
+	}
+	return client.GetNature(ctx, name)
+}
+
+// startBattle sets up a battle between the lead party member and a wild
+// Pokemon, which gets its level from the location explored last.
+func startBattle(ctx context.Context, cfg *Config, name string) (*battle, error) {
+	cfg.mu.RLock()
+	if cfg.Battle != nil {
+		cfg.mu.RUnlock()
+		return nil, fmt.Errorf("You are already battling %v", cfg.Battle.wild.owned.Pokemon)
+	}
+	if len(cfg.Profile.Party) == 0 {
+		cfg.mu.RUnlock()
+		return nil, fmt.Errorf("You have no Pokemon to battle with")
+	}
+	lead := cfg.Profile.Owned[cfg.Profile.Party[0]]
+	leadName := lead.Pokemon
+	levels, ok := cfg.Encounters[name]
+	cfg.mu.RUnlock()
+	if !ok {
+		levels = levelRange{defaultLevel, defaultLevel}
+	}
+
+	// The Pokedex does not keep moves, so the lead's come from the PokeAPI.
+	leadPkm, err := cfg.Client.GetPokemon(ctx, leadName)
+	if err != nil {
+		return nil, err
+	}
+
+	pkm, err := cfg.Client.GetPokemon(ctx, name)
+	var notFound *pokeapi.NotFoundError
+	if errors.As(err, &notFound) {
+		return nil, fmt.Errorf("no Pokemon named %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	species, err := cfg.Client.GetPokemonSpecies(ctx, pkm.Species.Name)
+	if err != nil {
+		return nil, err
+	}
+	natures, err := cfg.Client.ListNatures(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wild := rollOwned(cfg.Rand, pkm.Name, levels, resourceNames(natures, nil), species.GenderRate)
+
+	chart, err := loadTypeChart(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	b := &battle{captureRate: species.CaptureRate, chart: chart}
+	if b.player, err = loadCombatant(ctx, cfg.Client, lead, leadPkm); err != nil {
+		return nil, err
+	}
+	if b.wild, err = loadCombatant(ctx, cfg.Client, wild, pkm); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// loadCombatant fetches the nature and moves of o.
+func loadCombatant(ctx context.Context, client *pokeapi.Client, o *OwnedPokemon, pkm pokeapi.Pokemon) (*combatant, error) {
+	nature, err := loadNature(ctx, client, o.Nature)
+	if err != nil {
+		return nil, err
+	}
+	moves, err := loadMoves(ctx, client, knownMoves(pkm, o.Level))
+	if err != nil {
+		return nil, err
+	}
+	return newCombatant(o, newPokedexEntry(pkm), nature, moves), nil
+}
+
+func commandBattle(ctx context.Context, cfg *Config, args commandArgs) error {
+	name := args.arg(0)
+	if name == "" {
+		cfg.mu.RLock()
+		defer cfg.mu.RUnlock()
+		if cfg.Battle == nil {
+			return fmt.Errorf("You are not in a battle. Usage: battle <pokemon>")
+		}
+		cfg.Battle.printStatus()
+		return nil
+	}
+	return enterBattle(ctx, cfg, name)
+}
+
+// enterBattle starts a battle with a wild name and sends out the lead.
+func enterBattle(ctx context.Context, cfg *Config, name string) error {
+	b, err := startBattle(ctx, cfg, name)
+	if err != nil {
+		return err
+	}
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.Profile.Seen[name] = true
+	cfg.Battle = b
+	fmt.Printf("A wild %v appeared!\n", name)
+	fmt.Printf("Go, %v!\n", b.player.name())
+	b.printStatus()
+	return nil
+}
+
+func commandFight(ctx context.Context, cfg *Config, args commandArgs) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	b := cfg.Battle
+	if b == nil {
+		return fmt.Errorf("You are not in a battle")
+	}
+	move, ok := b.player.move(args.arg(0))
+	if !ok {
+		return fmt.Errorf("%v doesn't know %v. It knows %s", b.player.name(), args.arg(0), strings.Join(b.player.moveNames(), ", "))
+	}
+	printLines(b.round(cfg.Rand, move))
+	finish(cfg, b)
+	return nil
+}
+
+func commandRun(ctx context.Context, cfg *Config, args commandArgs) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	if cfg.Battle == nil {
+		return fmt.Errorf("You are not in a battle")
+	}
+	cfg.Battle = nil
+	fmt.Println("Got away safely!")
+	return nil
+}
+
+// leaveBattle ends the battle in progress, if any, and forgets the location
+// explored last. Both belong to the current profile, so this must happen
+// whenever the profile or its Pokemon are replaced. cfg.mu must be held.
+func leaveBattle(cfg *Config) {
+	if cfg.Battle != nil {
+		fmt.Printf("Left the battle with the wild %v\n", cfg.Battle.wild.owned.Pokemon)
+	}
+	cfg.Battle = nil
+	cfg.Encounters = nil
+}
+
+// checkNotBattling refuses to let the Pokemon ref names leave the party while
+// it is battling. cfg.mu must be held.
+func checkNotBattling(cfg *Config, ref string) error {
+	if cfg.Battle == nil {
+		return nil
+	}
+	if o, err := cfg.Profile.findOwned(ref); err == nil && o == cfg.Battle.player.owned {
+		return fmt.Errorf("%s is battling, finish the battle or run first", o.label())
+	}
+	return nil
+}
+
+// catchInBattle throws a ball at the wild Pokemon being battled. If it
+// breaks free, the wild Pokemon attacks.
+func catchInBattle(cfg *Config, b *battle, name, ball string, modifier float64) error {
+	if name != b.wild.owned.Pokemon {
+		return fmt.Errorf("You are battling %v, catch it or run first", b.wild.owned.Pokemon)
+	}
+	caught, err := throwBall(cfg, b.wild.owned, b.wild.entry, b.captureRate, ball, modifier, b.wild.stats["hp"], b.wild.hp)
+	if err != nil {
+		return err
+	}
+	if caught {
+		cfg.Battle = nil
+		return nil
+	}
+	printLines(b.wildTurn(cfg.Rand))
+	finish(cfg, b)
+	return nil
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+func TestBaseDamage(t *testing.T) {
+	cases := []struct {
+		level, power, attack, defense int
+		expected                      int
+	}{
+		{level: 50, power: 80, attack: 100, defense: 100, expected: 37},
+		{level: 100, power: 100, attack: 200, defense: 100, expected: 170},
+		{level: 5, power: 40, attack: 10, defense: 10, expected: 5},
+		{level: 5, power: 40, attack: 10, defense: 0, expected: 34},
+	}
+
+	for _, c := range cases {
+		if actual := baseDamage(c.level, c.power, c.attack, c.defense); actual != c.expected {
+			t.Errorf("%+v: expected %v, got %v", c, c.expected, actual)
+		}
+	}
+}
+
+func TestKnownMoves(t *testing.T) {
+	pkm := pokeapi.Pokemon{}
+	raw := `{"moves": [
+		{"move": {"name": "bite"}, "version_group_details": [{"level_learned_at": 13, "move_learn_method": {"name": "level-up"}}]},
+		{"move": {"name": "tackle"}, "version_group_details": [{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}}]},
+		{"move": {"name": "dig"}, "version_group_details": [{"level_learned_at": 0, "move_learn_method": {"name": "machine"}}]},
+		{"move": {"name": "quick-attack"}, "version_group_details": [
+			{"level_learned_at": 11, "move_learn_method": {"name": "level-up"}},
+			{"level_learned_at": 9, "move_learn_method": {"name": "level-up"}}]},
+		{"move": {"name": "growl"}, "version_group_details": [{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}}]},
+		{"move": {"name": "leer"}, "version_group_details": [{"level_learned_at": 7, "move_learn_method": {"name": "level-up"}}]}
+	]}`
+	if err := json.Unmarshal([]byte(raw), &pkm); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		level    int
+		expected string
+	}{
+		{level: 1, expected: "growl,tackle"},
+		{level: 10, expected: "growl,tackle,leer,quick-attack"},
+		{level: 15, expected: "tackle,leer,quick-attack,bite"},
+	}
+
+	for _, c := range cases {
+		if actual := strings.Join(knownMoves(pkm, c.level), ","); actual != c.expected {
+			t.Errorf("level %v: expected %v, got %v", c.level, c.expected, actual)
+		}
+	}
+}
+
+func TestAwardEVs(t *testing.T) {
+	defeated := PokedexEntry{Stats: []BaseStat{{Name: "attack", Effort: 2}, {Name: "speed", Effort: 1}}}
+
+	o := &OwnedPokemon{}
+	awardEVs(o, defeated)
+	if o.EVs["attack"] != 2 || o.EVs["speed"] != 1 {
+		t.Errorf("expected 2 attack and 1 speed EVs, got %v", o.EVs)
+	}
+
+	o = &OwnedPokemon{EVs: map[string]int{"attack": 251, "hp": 252}}
+	awardEVs(o, defeated)
+	if o.EVs["attack"] != maxEV {
+		t.Errorf("expected attack EVs to stop at %v, got %v", maxEV, o.EVs["attack"])
+	}
+	if o.EVs["speed"] != 1 {
+		t.Errorf("expected 1 speed EV, got %v", o.EVs["speed"])
+	}
+
+	o = &OwnedPokemon{EVs: map[string]int{"hp": 252, "defense": 252, "speed": 5}}
+	awardEVs(o, defeated)
+	if o.EVs["attack"] != 1 || o.EVs["speed"] != 5 {
+		t.Errorf("expected the total to stop at %v, got %v", maxTotalEVs, o.EVs)
+	}
+}
+
+const pikachuJSON = `{"name": "pikachu", "species": {"name": "pikachu"}, "types": [{"type": {"name": "electric"}}],
+	"stats": [{"base_stat": 35, "stat": {"name": "hp"}}, {"base_stat": 55, "stat": {"name": "attack"}},
+		{"base_stat": 40, "stat": {"name": "defense"}}, {"base_stat": 50, "stat": {"name": "special-attack"}},
+		{"base_stat": 50, "stat": {"name": "special-defense"}}, {"base_stat": 90, "stat": {"name": "speed"}}],
+	"moves": [{"move": {"name": "thunder-shock"}, "version_group_details": [{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}}]}]}`
+
+// battleHandler serves a level 50 pikachu's and a wild pidgey's data.
+func battleHandler(w http.ResponseWriter, r *http.Request) {
+	stats := func(hp, atk, def, spa, spd, spe int) string {
+		return fmt.Sprintf(`[{"base_stat": %d, "stat": {"name": "hp"}}, {"base_stat": %d, "stat": {"name": "attack"}},
+			{"base_stat": %d, "stat": {"name": "defense"}}, {"base_stat": %d, "stat": {"name": "special-attack"}},
+			{"base_stat": %d, "stat": {"name": "special-defense"}}, {"base_stat": %d, "effort": 1, "stat": {"name": "speed"}}]`,
+			hp, atk, def, spa, spd, spe)
+	}
+	levelUp := func(move string) string {
+		return fmt.Sprintf(`[{"move": {"name": %q}, "version_group_details": [{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}}]}]`, move)
+	}
+	switch r.URL.Path {
+	case "/pokemon/pikachu":
+		fmt.Fprint(w, pikachuJSON)
+	case "/pokemon/pidgey":
+		fmt.Fprintf(w, `{"name": "pidgey", "species": {"name": "pidgey"}, "types": [{"type": {"name": "normal"}}, {"type": {"name": "flying"}}],
+			"stats": %s, "moves": %s}`, stats(40, 45, 40, 35, 35, 56), levelUp("tackle"))
+	case "/pokemon-species/pidgey":
+		fmt.Fprint(w, `{"name": "pidgey", "capture_rate": 255, "gender_rate": 4}`)
+	case "/nature":
+		fmt.Fprint(w, `{"results": [{"name": "hardy"}]}`)
+	case "/nature/hardy":
+		fmt.Fprint(w, `{"name": "hardy", "increased_stat": {"name": "attack"}, "decreased_stat": {"name": "attack"}}`)
+	case "/move/thunder-shock":
+		fmt.Fprint(w, `{"name": "thunder-shock", "power": 40, "accuracy": 100, "damage_class": {"name": "special"}, "type": {"name": "electric"}}`)
+	case "/move/tackle":
+		fmt.Fprint(w, `{"name": "tackle", "power": 40, "accuracy": 100, "damage_class": {"name": "physical"}, "type": {"name": "normal"}}`)
+	case "/type":
+		fmt.Fprint(w, `{"results": [{"name": "normal"}, {"name": "flying"}, {"name": "electric"}]}`)
+	case "/type/flying":
+		fmt.Fprint(w, `{"name": "flying", "damage_relations": {"double_damage_from": [{"name": "electric"}]}}`)
+	case "/type/electric":
+		fmt.Fprint(w, `{"name": "electric", "damage_relations": {"double_damage_to": [{"name": "flying"}]}}`)
+	case "/type/normal":
+		fmt.Fprint(w, `{"name": "normal", "damage_relations": {"no_damage_to": [{"name": "ghost"}]}}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newBattleConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg := newTestConfig(t, battleHandler)
+	pikachu := pokeapi.Pokemon{}
+	if err := json.Unmarshal([]byte(pikachuJSON), &pikachu); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Profile.Pokedex["pikachu"] = newPokedexEntry(pikachu)
+	cfg.Profile.add(&OwnedPokemon{Pokemon: "pikachu", Level: 50, Nature: "hardy", IVs: rollIVs(cfg.Rand)})
+	return cfg
+}
+
+func TestBattleWin(t *testing.T) {
+	cfg := newBattleConfig(t)
+	ctx := context.Background()
+
+	if err := runCommand(ctx, cfg, commands["fight"], []string{"thunder-shock"}); err == nil {
+		t.Errorf("expected an error when fighting outside a battle")
+	}
+	if err := runCommand(ctx, cfg, commands["battle"], []string{"pidgey"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Battle == nil || cfg.Battle.wild.owned.Level != defaultLevel {
+		t.Fatalf("expected a battle with a level %v pidgey", defaultLevel)
+	}
+	if err := runCommand(ctx, cfg, commands["battle"], []string{"rattata"}); err == nil {
+		t.Errorf("expected an error when starting a second battle")
+	}
+	if err := runCommand(ctx, cfg, commands["fight"], []string{"surf"}); err == nil {
+		t.Errorf("expected an error for a move pikachu doesn't know")
+	}
+
+	// A level 50 pikachu knocks out a level 5 pidgey in one super effective hit.
+	if err := runCommand(ctx, cfg, commands["fight"], []string{"thunder-shock"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Battle != nil {
+		t.Fatalf("expected the battle to be over, wild HP %v", cfg.Battle.wild.hp)
+	}
+	if ev := cfg.Profile.Owned[1].EVs["speed"]; ev != 1 {
+		t.Errorf("expected pikachu to gain pidgey's speed EV, got %v", ev)
+	}
+	if !cfg.Profile.Seen["pidgey"] {
+		t.Errorf("expected pidgey to be seen")
+	}
+}
+
+func TestCatchInBattle(t *testing.T) {
+	cfg := newBattleConfig(t)
+	ctx := context.Background()
+	cfg.Encounters = map[string]levelRange{"pidgey": {7, 7}}
+
+	if err := runCommand(ctx, cfg, commands["battle"], []string{"pidgey"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wild := cfg.Battle.wild.owned
+	if err := runCommand(ctx, cfg, commands["catch"], []string{"rattata"}); err == nil {
+		t.Errorf("expected an error when catching something other than the wild Pokemon")
+	}
+	cfg.Profile.Bag["master-ball"] = 1
+	if err := runCommand(ctx, cfg, commands["catch"], []string{"pidgey", "--ball", "master-ball"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Battle != nil {
+		t.Errorf("expected catching to end the battle")
+	}
+	if o, err := cfg.Profile.findOwned("pidgey"); err != nil || o != wild || o.Level != 7 {
+		t.Errorf("expected the level 7 pidgey from the battle to be caught, got %v, %v", o, err)
+	}
+}
+
+func TestBattleCatchOddsImprove(t *testing.T) {
+	full := catchProbability(45, 1, 40, 40)
+	weakened := catchProbability(45, 1, 40, 4)
+	if weakened <= full {
+		t.Errorf("expected a weakened Pokemon to be easier to catch, got %.4f at full HP and %.4f at 4 HP", full, weakened)
+	}
+}
+
+func TestBattleLeadStaysInParty(t *testing.T) {
+	cfg := newBattleConfig(t)
+	ctx := context.Background()
+	cfg.Profile.Pokedex["pidgey"] = PokedexEntry{Name: "pidgey"}
+	cfg.Profile.add(&OwnedPokemon{Pokemon: "pidgey", Level: defaultLevel})
+
+	if err := runCommand(ctx, cfg, commands["battle"], []string{"pidgey"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := runCommand(ctx, cfg, commands["deposit"], []string{"pikachu"}); err == nil {
+		t.Errorf("expected an error when depositing the battling lead")
+	}
+	if err := runCommand(ctx, cfg, commands["party"], []string{"remove", "pikachu"}); err == nil {
+		t.Errorf("expected an error when removing the battling lead from the party")
+	}
+	if cfg.Profile.partySlot(1) != 0 {
+		t.Errorf("expected pikachu to stay the lead, party is %v", cfg.Profile.Party)
+	}
+	if err := runCommand(ctx, cfg, commands["deposit"], []string{"pidgey"}); err != nil {
+		t.Errorf("expected other party members to be deposited, got %v", err)
+	}
+}
+
+func TestBattleEndsWithProfile(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name    string
+		replace func(cfg *Config) error
+	}{
+		{
+			name: "switch",
+			replace: func(cfg *Config) error {
+				return runCommand(ctx, cfg, commands["profile"], []string{"new", "misty"})
+			},
+		},
+		{
+			name: "load",
+			replace: func(cfg *Config) error {
+				path := filepath.Join(t.TempDir(), "save.json")
+				if err := writeSave(path, newProfile("misty")); err != nil {
+					return err
+				}
+				return runCommand(ctx, cfg, commands["load"], []string{path})
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newBattleConfig(t)
+			cfg.DataDir = t.TempDir()
+			cfg.Encounters = map[string]levelRange{"pidgey": {7, 7}}
+			if err := runCommand(ctx, cfg, commands["battle"], []string{"pidgey"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := c.replace(cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Battle != nil || cfg.Encounters != nil {
+				t.Errorf("expected the battle and encounters to be cleared")
+			}
+			if err := runCommand(ctx, cfg, commands["fight"], []string{"thunder-shock"}); err == nil {
+				t.Errorf("expected an error when fighting after the battle ended")
+			}
+		})
+	}
+}
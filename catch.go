@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Catch odds follow the Generation III-IV formula used by the main series
+// games. A modified catch rate is computed from the species' capture_rate
+// (3 for legendaries up to 255 for the most common Pokemon), the ball and
+// how much HP the target has left:
+//
+//	a = (3*maxHP - 2*currentHP) * captureRate * ball / (3*maxHP)
+//
+// If a reaches 255 the Pokemon is caught outright. Otherwise the ball shakes
+// up to four times and each shake holds with probability b/65536, where
+//
+//	b = 1048560 / sqrt(sqrt(16711680 / a))
+//
+// so the overall chance is (b/65536)^4, roughly a/255. At full HP a Poke
+// Ball therefore catches a pidgey (rate 255) about a third of the time and a
+// mewtwo (rate 3) well under one percent of the time.
+
+// ballModifiers maps PokeAPI item names to their catch rate multiplier.
+var ballModifiers = map[string]float64{
+	"poke-ball":   1,
+	"great-ball":  1.5,
+	"ultra-ball":  2,
+	"master-ball": 255,
+}
+
+const defaultBall = "poke-ball"
+
+// modifiedCatchRate returns a from the formula above, clamped to [1, 255].
+// Missing or nonsensical inputs never panic; an unknown capture rate is
+// treated as the rarest possible one.
+func modifiedCatchRate(captureRate int, ball float64, maxHP, currentHP int) float64 {
+	if captureRate < 1 {
+		captureRate = 1
+	}
+	if ball <= 0 {
+		ball = 1
+	}
+	if maxHP < 1 {
+		maxHP = 1
+	}
+	if currentHP < 1 {
+		currentHP = 1
+	}
+	if currentHP > maxHP {
+		currentHP = maxHP
+	}
+	a := float64(3*maxHP-2*currentHP) * float64(captureRate) * ball / float64(3*maxHP)
+	return math.Max(1, math.Min(255, a))
+}
+
+func shakeThreshold(a float64) int {
+	return int(1048560 / math.Sqrt(math.Sqrt(16711680/a)))
+}
+
+// catchProbability is the exact chance that attemptCatch succeeds.
+func catchProbability(captureRate int, ball float64, maxHP, currentHP int) float64 {
+	a := modifiedCatchRate(captureRate, ball, maxHP, currentHP)
+	if a >= 255 {
+		return 1
+	}
+	return math.Pow(float64(shakeThreshold(a))/65536, 4)
+}
+
+// attemptCatch rolls the shake checks with rng and reports whether the
+// Pokemon was caught and how many times the ball shook before that was
+// decided.
+func attemptCatch(rng *rand.Rand, captureRate int, ball float64, maxHP, currentHP int) (caught bool, shakes int) {
+	a := modifiedCatchRate(captureRate, ball, maxHP, currentHP)
+	if a >= 255 {
+		return true, 4
+	}
+	b := shakeThreshold(a)
+	for shakes = 0; shakes < 4; shakes++ {
+		if rng.Intn(65536) >= b {
+			return false, shakes
+		}
+	}
+	return true, shakes
+}
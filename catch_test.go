@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+func TestCatchProbability(t *testing.T) {
+	cases := []struct {
+		name        string
+		captureRate int
+		ball        float64
+		maxHP       int
+		currentHP   int
+		expected    float64
+	}{
+		{name: "pidgey full hp", captureRate: 255, ball: 1, maxHP: 40, currentHP: 40, expected: 0.3331},
+		{name: "pidgey great ball", captureRate: 255, ball: 1.5, maxHP: 40, currentHP: 40, expected: 0.5000},
+		{name: "mewtwo full hp", captureRate: 3, ball: 1, maxHP: 200, currentHP: 200, expected: 0.0039},
+		{name: "pidgey one hp", captureRate: 255, ball: 1, maxHP: 40, currentHP: 1, expected: 0.9833},
+		{name: "master ball", captureRate: 3, ball: 255, maxHP: 200, currentHP: 200, expected: 1},
+		{name: "missing capture rate", captureRate: 0, ball: 1, maxHP: 0, currentHP: 0, expected: 0.0039},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := catchProbability(c.captureRate, c.ball, c.maxHP, c.currentHP)
+			if math.Abs(actual-c.expected) > 0.0005 {
+				t.Errorf("expected %.4f, got %.4f", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAttemptCatchIsDeterministic(t *testing.T) {
+	roll := func() []bool {
+		rng := rand.New(rand.NewSource(42))
+		var results []bool
+		for i := 0; i < 20; i++ {
+			caught, _ := attemptCatch(rng, 45, 1, 1, 1)
+			results = append(results, caught)
+		}
+		return results
+	}
+	first, second := roll(), roll()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected identical outcomes for the same seed, differed at attempt %v", i)
+		}
+	}
+}
+
+func TestAttemptCatchMatchesProbability(t *testing.T) {
+	const attempts = 20000
+	rng := rand.New(rand.NewSource(1))
+	caught := 0
+	for i := 0; i < attempts; i++ {
+		if ok, _ := attemptCatch(rng, 255, 1, 1, 1); ok {
+			caught++
+		}
+	}
+	expected := catchProbability(255, 1, 1, 1)
+	if actual := float64(caught) / attempts; math.Abs(actual-expected) > 0.02 {
+		t.Errorf("expected a catch rate near %.3f, got %.3f", expected, actual)
+	}
+}
+
+func newTestConfig(t *testing.T, handler http.HandlerFunc) *Config {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &Config{
+		Client:  pokeapi.NewClient(pokeapi.WithBaseURL(srv.URL)),
+		Profile: newProfile("ash"),
+		Rand:    rand.New(rand.NewSource(1)),
+	}
+}
+
+func pidgeyHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/pokemon/pidgey":
+		fmt.Fprint(w, `{"name": "pidgey", "species": {"name": "pidgey"}}`)
+	case "/pokemon-species/pidgey":
+		fmt.Fprint(w, `{"name": "pidgey", "capture_rate": 255, "gender_rate": 4}`)
+	case "/nature":
+		fmt.Fprint(w, `{"results": [{"name": "hardy"}, {"name": "jolly"}]}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestCatchConsumesBall(t *testing.T) {
+	// The trainer has no Pokemon to battle with, so the ball is thrown
+	// straight away.
+	cfg := newTestConfig(t, pidgeyHandler)
+	cfg.Profile.Bag = Bag{"great-ball": 1}
+
+	if err := runCommand(context.Background(), cfg, commands["catch"], []string{"pidgey", "--ball", "ultra-ball"}); err == nil {
+		t.Errorf("expected an error when throwing a ball the trainer does not have")
+	}
+	if err := runCommand(context.Background(), cfg, commands["catch"], []string{"pidgey", "--ball", "potion"}); err == nil {
+		t.Errorf("expected an error when throwing something that is not a ball")
+	}
+	if err := runCommand(context.Background(), cfg, commands["catch"], []string{"pidgey", "--ball", "great-ball"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := cfg.Profile.Bag["great-ball"]; n != 0 {
+		t.Errorf("expected the great ball to be used up, %v left", n)
+	}
+	if !cfg.Profile.Seen["pidgey"] {
+		t.Errorf("expected pidgey to be seen")
+	}
+	if cfg.Battle != nil {
+		t.Errorf("expected no battle without a party")
+	}
+}
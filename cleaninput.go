@@ -4,6 +4,13 @@ import (
 	"strings"
 )
 
+// CleanInput splits a command line into words and lowercases the command
+// name. Arguments keep their case; parseArgs lowercases those that are not
+// case sensitive.
 func CleanInput(text string) []string {
-	return strings.Fields(strings.ToLower(text))
+	words := strings.Fields(text)
+	if len(words) > 0 {
+		words[0] = strings.ToLower(words[0])
+	}
+	return words
 }
@@ -1,362 +1,48 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"math/rand"
-	"net/http"
-	"os"
+	"sort"
 	"sync"
 
+	"github.com/UUest/pokecli/internal/pokeapi"
 	"github.com/UUest/pokecli/internal/pokecache"
 )
 
+// cliCommand is one REPL command. Its declared args and flags are checked
+// before callback runs, so callbacks can rely on every required positional
+// argument being present.
 type cliCommand struct {
 	name        string
 	description string
-	callback    func(*Config, ...string) error
-}
-
-type locationArea struct {
-	Count    int    `json:"count"`
-	Next     string `json:"next"`
-	Previous string `json:"previous"`
-	Results  []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"results"`
-}
-
-type exploreLocation struct {
-	EncounterMethodRates []struct {
-		EncounterMethod struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"encounter_method"`
-		VersionDetails []struct {
-			Rate    int `json:"rate"`
-			Version struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"encounter_method_rates"`
-	GameIndex int `json:"game_index"`
-	ID        int `json:"id"`
-	Location  struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"location"`
-	Name  string `json:"name"`
-	Names []struct {
-		Language struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"language"`
-		Name string `json:"name"`
-	} `json:"names"`
-	PokemonEncounters []struct {
-		Pokemon struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"pokemon"`
-		VersionDetails []struct {
-			EncounterDetails []struct {
-				Chance          int           `json:"chance"`
-				ConditionValues []interface{} `json:"condition_values"`
-				MaxLevel        int           `json:"max_level"`
-				Method          struct {
-					Name string `json:"name"`
-					URL  string `json:"url"`
-				} `json:"method"`
-				MinLevel int `json:"min_level"`
-			} `json:"encounter_details"`
-			MaxChance int `json:"max_chance"`
-			Version   struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"pokemon_encounters"`
-}
-
-type Pokemon struct {
-	Abilities []struct {
-		Ability struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"ability"`
-		IsHidden bool `json:"is_hidden"`
-		Slot     int  `json:"slot"`
-	} `json:"abilities"`
-	BaseExperience int `json:"base_experience"`
-	Cries          struct {
-		Latest string `json:"latest"`
-		Legacy string `json:"legacy"`
-	} `json:"cries"`
-	Forms []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"forms"`
-	GameIndices []struct {
-		GameIndex int `json:"game_index"`
-		Version   struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"version"`
-	} `json:"game_indices"`
-	Height                 int           `json:"height"`
-	HeldItems              []interface{} `json:"held_items"`
-	ID                     int           `json:"id"`
-	IsDefault              bool          `json:"is_default"`
-	LocationAreaEncounters string        `json:"location_area_encounters"`
-	Moves                  []struct {
-		Move struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"move"`
-		VersionGroupDetails []struct {
-			LevelLearnedAt  int `json:"level_learned_at"`
-			MoveLearnMethod struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"move_learn_method"`
-			Order        interface{} `json:"order"`
-			VersionGroup struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version_group"`
-		} `json:"version_group_details"`
-	} `json:"moves"`
-	Name          string `json:"name"`
-	Order         int    `json:"order"`
-	PastAbilities []struct {
-		Abilities []struct {
-			Ability  interface{} `json:"ability"`
-			IsHidden bool        `json:"is_hidden"`
-			Slot     int         `json:"slot"`
-		} `json:"abilities"`
-		Generation struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"generation"`
-	} `json:"past_abilities"`
-	PastTypes []interface{} `json:"past_types"`
-	Species   struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"species"`
-	Sprites struct {
-		BackDefault      string      `json:"back_default"`
-		BackFemale       interface{} `json:"back_female"`
-		BackShiny        string      `json:"back_shiny"`
-		BackShinyFemale  interface{} `json:"back_shiny_female"`
-		FrontDefault     string      `json:"front_default"`
-		FrontFemale      interface{} `json:"front_female"`
-		FrontShiny       string      `json:"front_shiny"`
-		FrontShinyFemale interface{} `json:"front_shiny_female"`
-		Other            struct {
-			DreamWorld struct {
-				FrontDefault string      `json:"front_default"`
-				FrontFemale  interface{} `json:"front_female"`
-			} `json:"dream_world"`
-			Home struct {
-				FrontDefault     string      `json:"front_default"`
-				FrontFemale      interface{} `json:"front_female"`
-				FrontShiny       string      `json:"front_shiny"`
-				FrontShinyFemale interface{} `json:"front_shiny_female"`
-			} `json:"home"`
-			OfficialArtwork struct {
-				FrontDefault string `json:"front_default"`
-				FrontShiny   string `json:"front_shiny"`
-			} `json:"official-artwork"`
-			Showdown struct {
-				BackDefault      string      `json:"back_default"`
-				BackFemale       interface{} `json:"back_female"`
-				BackShiny        string      `json:"back_shiny"`
-				BackShinyFemale  interface{} `json:"back_shiny_female"`
-				FrontDefault     string      `json:"front_default"`
-				FrontFemale      interface{} `json:"front_female"`
-				FrontShiny       string      `json:"front_shiny"`
-				FrontShinyFemale interface{} `json:"front_shiny_female"`
-			} `json:"showdown"`
-		} `json:"other"`
-		Versions struct {
-			GenerationI struct {
-				RedBlue struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"red-blue"`
-				Yellow struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"yellow"`
-			} `json:"generation-i"`
-			GenerationIi struct {
-				Crystal struct {
-					BackDefault           string `json:"back_default"`
-					BackShiny             string `json:"back_shiny"`
-					BackShinyTransparent  string `json:"back_shiny_transparent"`
-					BackTransparent       string `json:"back_transparent"`
-					FrontDefault          string `json:"front_default"`
-					FrontShiny            string `json:"front_shiny"`
-					FrontShinyTransparent string `json:"front_shiny_transparent"`
-					FrontTransparent      string `json:"front_transparent"`
-				} `json:"crystal"`
-				Gold struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"gold"`
-				Silver struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"silver"`
-			} `json:"generation-ii"`
-			GenerationIii struct {
-				Emerald struct {
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"emerald"`
-				FireredLeafgreen struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"firered-leafgreen"`
-				RubySapphire struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"ruby-sapphire"`
-			} `json:"generation-iii"`
-			GenerationIv struct {
-				DiamondPearl struct {
-					BackDefault      string      `json:"back_default"`
-					BackFemale       interface{} `json:"back_female"`
-					BackShiny        string      `json:"back_shiny"`
-					BackShinyFemale  interface{} `json:"back_shiny_female"`
-					FrontDefault     string      `json:"front_default"`
-					FrontFemale      interface{} `json:"front_female"`
-					FrontShiny       string      `json:"front_shiny"`
-					FrontShinyFemale interface{} `json:"front_shiny_female"`
-				} `json:"diamond-pearl"`
-				HeartgoldSoulsilver struct {
-					BackDefault      string      `json:"back_default"`
-					BackFemale       interface{} `json:"back_female"`
-					BackShiny        string      `json:"back_shiny"`
-					BackShinyFemale  interface{} `json:"back_shiny_female"`
-					FrontDefault     string      `json:"front_default"`
-					FrontFemale      interface{} `json:"front_female"`
-					FrontShiny       string      `json:"front_shiny"`
-					FrontShinyFemale interface{} `json:"front_shiny_female"`
-				} `json:"heartgold-soulsilver"`
-				Platinum struct {
-					BackDefault      string      `json:"back_default"`
-					BackFemale       interface{} `json:"back_female"`
-					BackShiny        string      `json:"back_shiny"`
-					BackShinyFemale  interface{} `json:"back_shiny_female"`
-					FrontDefault     string      `json:"front_default"`
-					FrontFemale      interface{} `json:"front_female"`
-					FrontShiny       string      `json:"front_shiny"`
-					FrontShinyFemale interface{} `json:"front_shiny_female"`
-				} `json:"platinum"`
-			} `json:"generation-iv"`
-			GenerationV struct {
-				BlackWhite struct {
-					Animated struct {
-						BackDefault      string      `json:"back_default"`
-						BackFemale       interface{} `json:"back_female"`
-						BackShiny        string      `json:"back_shiny"`
-						BackShinyFemale  interface{} `json:"back_shiny_female"`
-						FrontDefault     string      `json:"front_default"`
-						FrontFemale      interface{} `json:"front_female"`
-						FrontShiny       string      `json:"front_shiny"`
-						FrontShinyFemale interface{} `json:"front_shiny_female"`
-					} `json:"animated"`
-					BackDefault      string      `json:"back_default"`
-					BackFemale       interface{} `json:"back_female"`
-					BackShiny        string      `json:"back_shiny"`
-					BackShinyFemale  interface{} `json:"back_shiny_female"`
-					FrontDefault     string      `json:"front_default"`
-					FrontFemale      interface{} `json:"front_female"`
-					FrontShiny       string      `json:"front_shiny"`
-					FrontShinyFemale interface{} `json:"front_shiny_female"`
-				} `json:"black-white"`
-			} `json:"generation-v"`
-			GenerationVi struct {
-				OmegarubyAlphasapphire struct {
-					FrontDefault     string      `json:"front_default"`
-					FrontFemale      interface{} `json:"front_female"`
-					FrontShiny       string      `json:"front_shiny"`
-					FrontShinyFemale interface{} `json:"front_shiny_female"`
-				} `json:"omegaruby-alphasapphire"`
-				XY struct {
-					FrontDefault     string      `json:"front_default"`
-					FrontFemale      interface{} `json:"front_female"`
-					FrontShiny       string      `json:"front_shiny"`
-					FrontShinyFemale interface{} `json:"front_shiny_female"`
-				} `json:"x-y"`
-			} `json:"generation-vi"`
-			GenerationVii struct {
-				Icons struct {
-					FrontDefault string      `json:"front_default"`
-					FrontFemale  interface{} `json:"front_female"`
-				} `json:"icons"`
-				UltraSunUltraMoon struct {
-					FrontDefault     string      `json:"front_default"`
-					FrontFemale      interface{} `json:"front_female"`
-					FrontShiny       string      `json:"front_shiny"`
-					FrontShinyFemale interface{} `json:"front_shiny_female"`
-				} `json:"ultra-sun-ultra-moon"`
-			} `json:"generation-vii"`
-			GenerationViii struct {
-				Icons struct {
-					FrontDefault string      `json:"front_default"`
-					FrontFemale  interface{} `json:"front_female"`
-				} `json:"icons"`
-			} `json:"generation-viii"`
-		} `json:"versions"`
-	} `json:"sprites"`
-	Stats []struct {
-		BaseStat int `json:"base_stat"`
-		Effort   int `json:"effort"`
-		Stat     struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"stat"`
-	} `json:"stats"`
-	Types []struct {
-		Slot int `json:"slot"`
-		Type struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"type"`
-	} `json:"types"`
-	Weight int `json:"weight"`
+	args        []argSpec
+	flags       []flagSpec
+	callback    func(context.Context, *Config, commandArgs) error
+	// complete offers tab completion candidates for the next argument,
+	// given the arguments typed so far. It may be nil.
+	complete func(context.Context, *Config, []string) []string
 }
 
 type Config struct {
 	NextURL     string
 	PreviousURL string
 	Cache       *pokecache.Cache
-	Pokedex     map[string]Pokemon
-	mu          sync.RWMutex
+	Client      *pokeapi.Client
+	Profile     *Profile
+	Rand        *rand.Rand
+	DataDir     string
+	Output      string // one of outputFormats; empty means table
+	// Encounters are the levels Pokemon are met at in the location
+	// explored last, which caught Pokemon get their level from.
+	Encounters map[string]levelRange
+	// Battle is the wild battle in progress, if any.
+	Battle *battle
+	// TypeChart is loaded from the PokeAPI the first time it is needed.
+	TypeChart *typeChart
+	mu        sync.RWMutex
 }
 
 var commands map[string]cliCommand
@@ -386,222 +72,512 @@ func init() {
 		"explore": {
 			name:        "explore",
 			description: "Explore a location",
+			args:        []argSpec{{name: "location"}},
 			callback:    commandExplore,
+			complete:    completeExplore,
 		},
 		"catch": {
 			name:        "catch",
-			description: "Catch a Pokemon",
+			description: "Catch the Pokemon you are battling, starting a battle if needed",
+			args:        []argSpec{{name: "pokemon"}},
+			flags:       []flagSpec{{name: "ball", value: "ball"}},
 			callback:    commandCatch,
+			complete:    completeCatch,
 		},
 		"inspect": {
 			name:        "inspect",
 			description: "Inspect a Pokemon",
+			args:        []argSpec{{name: "pokemon"}},
 			callback:    commandInspect,
+			complete:    completeInspect,
 		},
 		"pokedex": {
 			name:        "pokedex",
-			description: "Display the Pokedex",
+			description: "Display the Pokedex, or its completion per generation and region",
+			args:        []argSpec{{name: "completion", optional: true}},
 			callback:    commandPokedex,
+			complete:    completeWords("completion"),
+		},
+		"save": {
+			name:        "save",
+			description: "Save the Pokedex",
+			args:        []argSpec{{name: "file", optional: true, caseSensitive: true}},
+			callback:    commandSave,
+		},
+		"load": {
+			name:        "load",
+			description: "Load a Pokedex from a save file",
+			args:        []argSpec{{name: "file", caseSensitive: true}},
+			callback:    commandLoad,
+		},
+		"bag": {
+			name:        "bag",
+			description: "Show the items in your bag",
+			callback:    commandBag,
+		},
+		"profile": {
+			name:        "profile",
+			description: "Show the current trainer profile, or manage them with new <name>, list and switch <name>",
+			args:        []argSpec{{name: "action", optional: true}, {name: "name", optional: true}},
+			callback:    commandProfile,
+			complete:    completeProfile,
+		},
+		"cache": {
+			name:        "cache",
+			description: "Inspect or manage the PokeAPI cache with stats, clear or keys",
+			args:        []argSpec{{name: "action", optional: true}},
+			callback:    commandCache,
+			complete:    completeWords("stats", "clear", "keys"),
+		},
+		"nickname": {
+			name:        "nickname",
+			description: "Give one of your Pokemon a nickname, or clear it",
+			args:        []argSpec{{name: "pokemon"}, {name: "nickname", optional: true}},
+			callback:    commandNickname,
+			complete:    completeInspect,
+		},
+		"party": {
+			name:        "party",
+			description: "Show your party, or change it with add <pokemon>, remove <pokemon> and swap <a> <b>",
+			args:        []argSpec{{name: "action", optional: true}, {name: "pokemon", optional: true}, {name: "with", optional: true}},
+			callback:    commandParty,
+			complete:    completeParty,
+		},
+		"box": {
+			name:        "box",
+			description: "List the Pokemon stored in your PC boxes",
+			args:        []argSpec{{name: "action", optional: true}},
+			callback:    commandBox,
+			complete:    completeWords("list"),
+		},
+		"withdraw": {
+			name:        "withdraw",
+			description: "Move a Pokemon from your PC to your party",
+			args:        []argSpec{{name: "pokemon"}},
+			callback:    commandWithdraw,
+			complete:    completeWithdraw,
+		},
+		"deposit": {
+			name:        "deposit",
+			description: "Move a Pokemon from your party to your PC",
+			args:        []argSpec{{name: "pokemon"}},
+			flags:       []flagSpec{{name: "box", value: "number"}},
+			callback:    commandDeposit,
+			complete:    completeDeposit,
+		},
+		"battle": {
+			name:        "battle",
+			description: "Battle a wild Pokemon with your lead party member, or show the battle in progress",
+			args:        []argSpec{{name: "pokemon", optional: true}},
+			callback:    commandBattle,
+			complete:    completeExplorePokemon,
+		},
+		"fight": {
+			name:        "fight",
+			description: "Use a move in the battle in progress",
+			args:        []argSpec{{name: "move"}},
+			callback:    commandFight,
+			complete:    completeFight,
+		},
+		"run": {
+			name:        "run",
+			description: "Run from the battle in progress",
+			callback:    commandRun,
+		},
+		"type": {
+			name:        "type",
+			description: "Show what a type is strong and weak against",
+			args:        []argSpec{{name: "type"}},
+			callback:    commandType,
+			complete:    completeType,
+		},
+		"weak": {
+			name:        "weak",
+			description: "Show which types a Pokemon is weak to and resists",
+			args:        []argSpec{{name: "pokemon"}},
+			callback:    commandWeak,
+			complete:    completeWeak,
+		},
+		"set": {
+			name:        "set",
+			description: "Change a setting, such as the output format with output <table|json|yaml|csv>",
+			args:        []argSpec{{name: "setting"}, {name: "value"}},
+			callback:    commandSet,
+			complete:    completeSet,
 		},
 	}
 }
 
-func commandExit(cfg *Config, param ...string) error {
-	err := fmt.Errorf("Closing the Pokedex... Goodbye!")
-	fmt.Printf("%v\n", err)
-	defer os.Exit(0)
-	return err
+// commandExit asks the REPL loop to stop. Saving and other cleanup happen in
+// the shutdown hooks that main runs on the way out.
+func commandExit(ctx context.Context, cfg *Config, args commandArgs) error {
+	fmt.Println("Closing the Pokedex... Goodbye!")
+	return errExit
 }
 
-func commandHelp(cfg *Config, param ...string) error {
+func commandHelp(ctx context.Context, cfg *Config, args commandArgs) error {
 	fmt.Println("Welcome to the Pokedex!")
 	fmt.Println("Usage:")
 	fmt.Println("")
-	for _, command := range commands {
-		fmt.Printf("%s: %s\n", command.name, command.description)
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		command := commands[name]
+		fmt.Printf("%s: %s\n", command.usage(), command.description)
 	}
 	return nil
 }
 
 var offset int = 0
 
-func commandMap(cfg *Config, param ...string) error {
-	url := cfg.NextURL
-	if url == "" {
-		url = "https://pokeapi.co/api/v2/location-area?limit=20&offset=0"
-	}
-	var raw []byte
-	if v, ok := cfg.Cache.Get(url); ok {
-		raw = v
-	} else {
-		res, err := http.Get(url)
-		if err != nil {
-			return fmt.Errorf("Failed to fetch data from PokeAPI: %v", err)
-		}
-		defer res.Body.Close()
-		raw, err = io.ReadAll(res.Body)
-		if err != nil {
-			return fmt.Errorf("Failed to read response body: %v", err)
-		}
-		cfg.Cache.Add(url, raw)
-	}
-	var locationAreaData locationArea
-	err := json.Unmarshal(raw, &locationAreaData)
+func commandMap(ctx context.Context, cfg *Config, args commandArgs) error {
+	locationAreaData, err := cfg.Client.ListLocationAreas(ctx, cfg.NextURL)
 	if err != nil {
-		return fmt.Errorf("Failed to parse data from PokeAPI: %v", err)
-	}
-	for _, location := range locationAreaData.Results {
-		fmt.Printf("%v\n", location.Name)
+		return err
 	}
 	cfg.NextURL = locationAreaData.Next
 	cfg.PreviousURL = locationAreaData.Previous
-	return nil
+	return renderLocationAreas(cfg, locationAreaData)
 }
 
-func commandMapb(cfg *Config, param ...string) error {
-	url := cfg.PreviousURL
-	if url == "" {
+func commandMapb(ctx context.Context, cfg *Config, args commandArgs) error {
+	if cfg.PreviousURL == "" {
 		return fmt.Errorf("you're on the first page")
 	}
-	var raw []byte
-	if v, ok := cfg.Cache.Get(url); ok {
-		raw = v
-	} else {
-		res, err := http.Get(url)
-		if err != nil {
-			return fmt.Errorf("Failed to fetch data from PokeAPI: %v", err)
-		}
-		defer res.Body.Close()
-		raw, err = io.ReadAll(res.Body)
-		if err != nil {
-			return fmt.Errorf("Failed to read response body: %v", err)
-		}
-		cfg.Cache.Add(url, raw)
-	}
-	locationAreaData := locationArea{}
-	err := json.Unmarshal(raw, &locationAreaData)
+	locationAreaData, err := cfg.Client.ListLocationAreas(ctx, cfg.PreviousURL)
 	if err != nil {
-		return fmt.Errorf("Failed to parse data from PokeAPI: %v", err)
-	}
-	for _, location := range locationAreaData.Results {
-		fmt.Printf("%v\n", location.Name)
+		return err
 	}
 	cfg.NextURL = locationAreaData.Next
 	cfg.PreviousURL = locationAreaData.Previous
-	return nil
+	return renderLocationAreas(cfg, locationAreaData)
 }
 
-func commandExplore(cfg *Config, location ...string) error {
-	if location == nil || len(location) == 0 {
-		return fmt.Errorf("Please provide a location")
-	}
-	url := fmt.Sprintf("https://pokeapi.co/api/v2/location-area/%s", location[0])
-	var raw []byte
-	if v, ok := cfg.Cache.Get(url); ok {
-		raw = v
-	} else {
-		res, err := http.Get(url)
-		if err != nil {
-			return fmt.Errorf("Failed to fetch data from PokeAPI: %v", err)
-		}
-		defer res.Body.Close()
-		raw, err = io.ReadAll(res.Body)
-		if err != nil {
-			return fmt.Errorf("Failed to read response body: %v", err)
+func renderLocationAreas(cfg *Config, list pokeapi.LocationAreaList) error {
+	records := make([]record, 0, len(list.Results))
+	for _, location := range list.Results {
+		records = append(records, record{{"name", location.Name}, {"url", location.URL}})
+	}
+	return render(cfg, records, func() {
+		for _, location := range list.Results {
+			fmt.Printf("%v\n", location.Name)
 		}
-		cfg.Cache.Add(url, raw)
+	})
+}
+
+func commandExplore(ctx context.Context, cfg *Config, args commandArgs) error {
+	location := args.arg(0)
+	exploreLocationData, err := cfg.Client.GetLocationArea(ctx, location)
+	var notFound *pokeapi.NotFoundError
+	if errors.As(err, &notFound) {
+		return fmt.Errorf("no location area named %s", location)
 	}
-	exploreLocationData := exploreLocation{}
-	err := json.Unmarshal(raw, &exploreLocationData)
 	if err != nil {
-		return fmt.Errorf("Failed to parse data from PokeAPI: %v", err)
+		return err
 	}
-	fmt.Printf("Exploring %s...\n", location[0])
 	if exploreLocationData.PokemonEncounters == nil {
 		return fmt.Errorf("No Pokemon encounters found")
 	}
-	fmt.Println("Found Pokemon:")
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	records := make([]record, 0, len(exploreLocationData.PokemonEncounters))
 	for _, encounter := range exploreLocationData.PokemonEncounters {
-		fmt.Printf("- %v\n", encounter.Pokemon.Name)
+		records = append(records, record{{"location", location}, {"pokemon", encounter.Pokemon.Name}})
+		cfg.Profile.Seen[encounter.Pokemon.Name] = true
 	}
-	return nil
+	cfg.Encounters = encounterLevels(exploreLocationData)
+	autosave(cfg)
+	return render(cfg, records, func() {
+		fmt.Printf("Exploring %s...\n", location)
+		fmt.Println("Found Pokemon:")
+		for _, encounter := range exploreLocationData.PokemonEncounters {
+			fmt.Printf("- %v\n", encounter.Pokemon.Name)
+		}
+	})
 }
 
-func commandCatch(cfg *Config, pokemon ...string) error {
-	if pokemon == nil || len(pokemon) == 0 {
-		return fmt.Errorf("Please provide a Pokemon")
-	}
-	url := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%s", pokemon[0])
-	var raw []byte
-	if v, ok := cfg.Cache.Get(url); ok {
-		raw = v
-	} else {
-		res, err := http.Get(url)
-		if err != nil {
-			return fmt.Errorf("Failed to fetch data from PokeAPI: %v", err)
-		}
-		defer res.Body.Close()
-		raw, err = io.ReadAll(res.Body)
-		if err != nil {
-			return fmt.Errorf("Failed to read response body: %v", err)
+func commandCatch(ctx context.Context, cfg *Config, args commandArgs) error {
+	name := args.arg(0)
+	ball := args.flag("ball", defaultBall)
+	modifier, ok := ballModifiers[ball]
+	if !ok {
+		return fmt.Errorf("%s is not a Poke Ball", ball)
+	}
+	cfg.mu.Lock()
+	if b := cfg.Battle; b != nil {
+		defer cfg.mu.Unlock()
+		return catchInBattle(cfg, b, name, ball, modifier)
+	}
+	canBattle := len(cfg.Profile.Party) > 0
+	cfg.mu.Unlock()
+	if canBattle {
+		// Pokemon are caught in battle, where weakening them first
+		// improves the odds.
+		if err := enterBattle(ctx, cfg, name); err != nil {
+			return err
 		}
-		cfg.Cache.Add(url, raw)
+		fmt.Printf("Weaken %v with fight, then throw a ball with catch\n", name)
+		return nil
+	}
+
+	pkm, err := cfg.Client.GetPokemon(ctx, name)
+	var notFound *pokeapi.NotFoundError
+	if errors.As(err, &notFound) {
+		return fmt.Errorf("no Pokemon named %s", name)
+	}
+	if err != nil {
+		return err
+	}
+	species, err := cfg.Client.GetPokemonSpecies(ctx, pkm.Species.Name)
+	if err != nil {
+		return err
 	}
-	pkm := Pokemon{}
-	err := json.Unmarshal(raw, &pkm)
+	natures, err := cfg.Client.ListNatures(ctx)
 	if err != nil {
-		return fmt.Errorf("Failed to parse data from PokeAPI: %v", err)
+		return err
 	}
-	fmt.Printf("Throwing a Pokeball at %v...\n", pokemon[0])
-	catchChance := rand.Intn(pkm.BaseExperience * rand.Intn(pkm.BaseExperience))
 	cfg.mu.Lock()
 	defer cfg.mu.Unlock()
-	if _, dup := cfg.Pokedex[pkm.Name]; dup {
-		fmt.Printf("%v is already caught!\n", pokemon[0])
-		return nil
+	cfg.Profile.Seen[pkm.Name] = true
+	levels, ok := cfg.Encounters[pkm.Name]
+	if !ok {
+		levels = levelRange{defaultLevel, defaultLevel}
 	}
-	if catchChance >= (pkm.BaseExperience * rand.Intn(pkm.BaseExperience)) {
-		cfg.Pokedex[pkm.Name] = pkm
-		fmt.Printf("%v was caught!\n", pokemon[0])
-	} else {
-		fmt.Printf("%v escaped!\n", pokemon[0])
+	wild := rollOwned(cfg.Rand, pkm.Name, levels, resourceNames(natures, nil), species.GenderRate)
+	// A trainer without Pokemon cannot battle, so their first catch is
+	// thrown at a Pokemon with full HP.
+	_, err = throwBall(cfg, wild, newPokedexEntry(pkm), species.CaptureRate, ball, modifier, 1, 1)
+	return err
+}
+
+// throwBall throws a ball at wild and adds it to the collection if it is
+// caught. cfg.mu must be held.
+func throwBall(cfg *Config, wild *OwnedPokemon, entry PokedexEntry, captureRate int, ball string, modifier float64, maxHP, currentHP int) (bool, error) {
+	if !cfg.Profile.Bag.take(ball) {
+		return false, fmt.Errorf("You have no %s left", ball)
 	}
-	return nil
+	fmt.Printf("Throwing a %v at %v...\n", ball, wild.Pokemon)
+	caught, shakes := attemptCatch(cfg.Rand, captureRate, modifier, maxHP, currentHP)
+	for i := 0; i < shakes && i < 3; i++ {
+		fmt.Println("...the ball shakes...")
+	}
+	if !caught {
+		fmt.Printf("%v escaped!\n", wild.Pokemon)
+		autosave(cfg)
+		return false, nil
+	}
+	cfg.Profile.Pokedex[entry.Name] = entry
+	box := cfg.Profile.add(wild)
+	fmt.Printf("%v was caught!\n", wild.Pokemon)
+	if wild.Shiny {
+		fmt.Println("It's shiny!")
+	}
+	fmt.Printf("Added %v to your collection\n", wild.label())
+	if box > 0 {
+		fmt.Printf("Your party is full, %v was sent to Box %d\n", wild.Pokemon, box)
+	}
+	autosave(cfg)
+	return true, nil
 }
 
-func commandInspect(cfg *Config, pokemon ...string) error {
-	if pokemon == nil || len(pokemon) == 0 {
-		return fmt.Errorf("Please provide a Pokemon")
-	}
-	if pkm, ok := cfg.Pokedex[pokemon[0]]; ok {
-		fmt.Printf("Inspecting %v...\n", pokemon[0])
-		fmt.Printf("Name: %v\n", pkm.Name)
-		fmt.Printf("Height: %v\n", pkm.Height)
-		fmt.Printf("Weight: %v\n", pkm.Weight)
-		fmt.Println("Stats:")
-		fmt.Printf("   - HP: %v\n", pkm.Stats[0].BaseStat)
-		fmt.Printf("   - Attack: %v\n", pkm.Stats[1].BaseStat)
-		fmt.Printf("   - Defense: %v\n", pkm.Stats[2].BaseStat)
-		fmt.Printf("   - Special Attack: %v\n", pkm.Stats[3].BaseStat)
-		fmt.Printf("   - Special Defense: %v\n", pkm.Stats[4].BaseStat)
-		fmt.Printf("   - Speed: %v\n", pkm.Stats[5].BaseStat)
-		fmt.Println("Type:")
-		for _, t := range pkm.Types {
-			fmt.Printf("   - %v\n", t.Type.Name)
+func commandInspect(ctx context.Context, cfg *Config, args commandArgs) error {
+	name := args.arg(0)
+	cfg.mu.RLock()
+	found, err := cfg.Profile.findOwned(name)
+	if err != nil {
+		cfg.mu.RUnlock()
+		return err
+	}
+	o := *found
+	entry := cfg.Profile.Pokedex[o.Pokemon]
+	cfg.mu.RUnlock()
+
+	// The Pokedex keeps what stats need; the rest, such as height and
+	// weight, comes from the PokeAPI.
+	pkm, err := cfg.Client.GetPokemon(ctx, o.Pokemon)
+	if err != nil {
+		return err
+	}
+	nature := pokeapi.Nature{}
+	if o.Nature != "" {
+		if nature, err = cfg.Client.GetNature(ctx, o.Nature); err != nil {
+			return err
 		}
-		return nil
 	}
-	return fmt.Errorf("Pokemon not found in Pokedex")
+	stats := computeStats(entry, &o, nature)
+	return render(cfg, []record{ownedRecord(&o, pkm, stats)}, func() {
+		printPokemon(name, pkm, stats)
+		printOwned(&o)
+	})
 }
 
-func commandPokedex(cfg *Config, pokemon ...string) error {
-	fmt.Println("Pokedex:")
-	if len(cfg.Pokedex) == 0 {
-		fmt.Println("No Pokemon caught yet!")
-		return nil
+// ownedRecord describes an owned Pokemon together with the data of its kind.
+func ownedRecord(o *OwnedPokemon, pkm pokeapi.Pokemon, stats []statLine) record {
+	computed, ivs, evs := record{}, record{}, record{}
+	for _, line := range stats {
+		computed = append(computed, field{line.name, line.value})
+		ivs = append(ivs, field{line.name, line.iv})
+		evs = append(evs, field{line.name, line.ev})
+	}
+	r := append(record{{"id", o.ID}}, pokemonRecord(pkm)...)
+	return append(r,
+		field{"nickname", o.Nickname},
+		field{"level", o.Level},
+		field{"nature", o.Nature},
+		field{"gender", o.Gender},
+		field{"shiny", o.Shiny},
+		field{"computed_stats", computed},
+		field{"ivs", ivs},
+		field{"evs", evs},
+	)
+}
+
+func pokemonRecord(pkm pokeapi.Pokemon) record {
+	stats := record{}
+	for _, s := range pkm.Stats {
+		stats = append(stats, field{s.Stat.Name, s.BaseStat})
+	}
+	return record{
+		{"name", pkm.Name},
+		{"height", pkm.Height},
+		{"weight", pkm.Weight},
+		{"stats", stats},
+		{"types", pokemonTypes(pkm)},
+	}
+}
+
+func pokemonTypes(pkm pokeapi.Pokemon) []string {
+	types := make([]string, 0, len(pkm.Types))
+	for _, t := range pkm.Types {
+		types = append(types, t.Type.Name)
+	}
+	return types
+}
+
+func printPokemon(name string, pkm pokeapi.Pokemon, stats []statLine) {
+	fmt.Printf("Inspecting %v...\n", name)
+	fmt.Printf("Name: %v\n", pkm.Name)
+	fmt.Printf("Height: %v\n", pkm.Height)
+	fmt.Printf("Weight: %v\n", pkm.Weight)
+	printStats(stats)
+	fmt.Println("Type:")
+	for _, t := range pkm.Types {
+		fmt.Printf("   - %v\n", t.Type.Name)
+	}
+}
+
+func printOwned(o *OwnedPokemon) {
+	fmt.Printf("ID: #%v\n", o.ID)
+	if o.Nickname != "" {
+		fmt.Printf("Nickname: %v\n", o.Nickname)
 	}
-	for _, pkm := range cfg.Pokedex {
-		fmt.Printf("   - %v\n", pkm.Name)
+	fmt.Printf("Level: %v\n", o.Level)
+	fmt.Printf("Nature: %v\n", valueOr(o.Nature, "unknown"))
+	fmt.Printf("Gender: %v\n", valueOr(o.Gender, "unknown"))
+	if o.Shiny {
+		fmt.Println("Shiny: yes")
+	}
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func commandPokedex(ctx context.Context, cfg *Config, args commandArgs) error {
+	if view := args.arg(0); view != "" {
+		if view != "completion" {
+			return fmt.Errorf("Unknown pokedex view: %s", view)
+		}
+		return printCompletion(ctx, cfg)
+	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	names := make([]string, 0, len(cfg.Profile.Pokedex))
+	for name := range cfg.Profile.Pokedex {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	owned := make(map[string]int, len(names))
+	for _, o := range cfg.Profile.Owned {
+		owned[o.Pokemon]++
+	}
+	records := make([]record, 0, len(names))
+	for _, name := range names {
+		entry := cfg.Profile.Pokedex[name]
+		records = append(records, record{
+			{"name", entry.Name},
+			{"species", entry.Species},
+			{"types", entry.Types},
+			{"owned", owned[name]},
+		})
+	}
+	return render(cfg, records, func() {
+		fmt.Println("Pokedex:")
+		fmt.Printf("Seen: %d, Caught: %d\n", len(cfg.Profile.Seen), len(cfg.Profile.Pokedex))
+		if len(cfg.Profile.Pokedex) == 0 {
+			fmt.Println("No Pokemon caught yet!")
+			return
+		}
+		for _, pkm := range cfg.Profile.Pokedex {
+			if n := owned[pkm.Name]; n > 1 {
+				fmt.Printf("   - %v x%d\n", pkm.Name, n)
+			} else {
+				fmt.Printf("   - %v\n", pkm.Name)
+			}
+		}
+	})
+}
+
+func commandCache(ctx context.Context, cfg *Config, args commandArgs) error {
+	action := args.arg(0)
+	if action == "" || action == "stats" {
+		stats := cfg.Cache.Stats()
+		r := record{
+			{"entries", stats.Entries},
+			{"bytes", stats.Bytes},
+			{"hits", stats.Hits},
+			{"disk_hits", stats.DiskHits},
+			{"misses", stats.Misses},
+			{"evictions", stats.Evictions},
+			{"reaped", stats.Reaped},
+		}
+		return render(cfg, []record{r}, func() {
+			fmt.Println("Cache stats:")
+			fmt.Printf("   - Entries: %v\n", stats.Entries)
+			fmt.Printf("   - Size: %v bytes\n", stats.Bytes)
+			fmt.Printf("   - Hits: %v (%v from disk)\n", stats.Hits, stats.DiskHits)
+			fmt.Printf("   - Misses: %v\n", stats.Misses)
+			fmt.Printf("   - Evictions: %v\n", stats.Evictions)
+			fmt.Printf("   - Reaped: %v\n", stats.Reaped)
+		})
+	}
+	switch action {
+	case "clear":
+		cfg.Cache.Clear()
+		fmt.Println("Cache cleared!")
+	case "keys":
+		keys := cfg.Cache.Keys()
+		records := make([]record, 0, len(keys))
+		for _, key := range keys {
+			records = append(records, record{{"key", key}})
+		}
+		return render(cfg, records, func() {
+			if len(keys) == 0 {
+				fmt.Println("Cache is empty!")
+				return
+			}
+			for _, key := range keys {
+				fmt.Printf("   - %v\n", key)
+			}
+		})
+	default:
+		return fmt.Errorf("Unknown cache action: %s", action)
 	}
 	return nil
 }
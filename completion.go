@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// completion counts how many of species have been seen and caught.
+func completion(species []string, seen, caught map[string]bool) (seenCount, caughtCount int) {
+	for _, name := range species {
+		if caught[name] {
+			caughtCount++
+		}
+		if seen[name] || caught[name] {
+			seenCount++
+		}
+	}
+	return seenCount, caughtCount
+}
+
+// progressSets snapshots the current profile as sets of seen and caught
+// names. Caught Pokemon are recorded under both their own and their species
+// name, since regional Pokedexes list species ("deoxys") rather than forms
+// ("deoxys-normal").
+func progressSets(cfg *Config) (seen, caught map[string]bool) {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	seen = make(map[string]bool, len(cfg.Profile.Seen))
+	for name := range cfg.Profile.Seen {
+		seen[name] = true
+	}
+	caught = make(map[string]bool, 2*len(cfg.Profile.Pokedex))
+	for name, entry := range cfg.Profile.Pokedex {
+		caught[name] = true
+		caught[entry.Species] = true
+	}
+	return seen, caught
+}
+
+// completionRow is the completion of one generation or region.
+type completionRow struct {
+	group               string
+	name                string
+	seen, caught, total int
+}
+
+func newCompletionRow(group, name string, species []string, seen, caught map[string]bool) completionRow {
+	seenCount, caughtCount := completion(species, seen, caught)
+	return completionRow{group: group, name: name, seen: seenCount, caught: caughtCount, total: len(species)}
+}
+
+func printCompletionLine(row completionRow) {
+	fmt.Printf("   - %v: seen %d/%d (%.1f%%), caught %d/%d (%.1f%%)\n",
+		row.name,
+		row.seen, row.total, 100*float64(row.seen)/float64(row.total),
+		row.caught, row.total, 100*float64(row.caught)/float64(row.total))
+}
+
+// printCompletion shows Pokedex completion per generation and per region.
+// A region is measured against its first Pokedex, which PokeAPI lists as the
+// region's original regional dex.
+func printCompletion(ctx context.Context, cfg *Config) error {
+	seen, caught := progressSets(cfg)
+	var rows []completionRow
+
+	generations, err := cfg.Client.ListGenerations(ctx)
+	if err != nil {
+		return err
+	}
+	for _, result := range generations.Results {
+		generation, err := cfg.Client.GetGeneration(ctx, result.Name)
+		if err != nil {
+			return err
+		}
+		species := make([]string, 0, len(generation.PokemonSpecies))
+		for _, s := range generation.PokemonSpecies {
+			species = append(species, s.Name)
+		}
+		rows = append(rows, newCompletionRow("generation", generation.Name, species, seen, caught))
+	}
+
+	regions, err := cfg.Client.ListRegions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, result := range regions.Results {
+		region, err := cfg.Client.GetRegion(ctx, result.Name)
+		if err != nil {
+			return err
+		}
+		if len(region.Pokedexes) == 0 {
+			continue
+		}
+		pokedex, err := cfg.Client.GetPokedex(ctx, region.Pokedexes[0].Name)
+		if err != nil {
+			return err
+		}
+		species := make([]string, 0, len(pokedex.PokemonEntries))
+		for _, entry := range pokedex.PokemonEntries {
+			species = append(species, entry.PokemonSpecies.Name)
+		}
+		rows = append(rows, newCompletionRow("region", region.Name, species, seen, caught))
+	}
+
+	var records []record
+	for _, row := range rows {
+		if row.total == 0 {
+			continue
+		}
+		records = append(records, record{
+			{"group", row.group},
+			{"name", row.name},
+			{"seen", row.seen},
+			{"caught", row.caught},
+			{"total", row.total},
+		})
+	}
+	return render(cfg, records, func() {
+		fmt.Println("Completion by generation:")
+		for _, row := range rows {
+			if row.group == "generation" && row.total > 0 {
+				printCompletionLine(row)
+			}
+		}
+		fmt.Println("Completion by region:")
+		for _, row := range rows {
+			if row.group == "region" && row.total > 0 {
+				printCompletionLine(row)
+			}
+		}
+	})
+}
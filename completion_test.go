@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	species := []string{"bulbasaur", "ivysaur", "venusaur", "charmander"}
+	seen := map[string]bool{"ivysaur": true, "charmander": true, "pidgey": true}
+	caught := map[string]bool{"bulbasaur": true, "charmander": true}
+
+	seenCount, caughtCount := completion(species, seen, caught)
+	if seenCount != 3 {
+		t.Errorf("expected 3 seen, got %v", seenCount)
+	}
+	if caughtCount != 2 {
+		t.Errorf("expected 2 caught, got %v", caughtCount)
+	}
+}
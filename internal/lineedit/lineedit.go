@@ -0,0 +1,376 @@
+// Package lineedit is a small readline replacement: Emacs-style line
+// editing, history persisted to a file and tab completion, on Unix
+// terminals. When input is not a terminal it reads plain lines.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupt is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupt = errors.New("interrupted")
+
+const defaultMaxHistory = 1000
+
+// Completer returns every candidate for the word being typed at the end of
+// line. The editor keeps those that start with the partial word.
+type Completer func(line string) []string
+
+type Editor struct {
+	in          *os.File
+	reader      *bufio.Reader
+	out         io.Writer
+	history     []string
+	historyPath string
+	maxHistory  int
+	complete    Completer
+}
+
+func New(in *os.File, out io.Writer) *Editor {
+	e := newEditor(in, out)
+	e.in = in
+	return e
+}
+
+func newEditor(in io.Reader, out io.Writer) *Editor {
+	return &Editor{
+		reader:     bufio.NewReader(in),
+		out:        out,
+		maxHistory: defaultMaxHistory,
+	}
+}
+
+func (e *Editor) SetCompleter(complete Completer) {
+	e.complete = complete
+}
+
+// Interactive reports whether the editor reads from a terminal.
+func (e *Editor) Interactive() bool {
+	return e.in != nil && isTerminal(e.in.Fd())
+}
+
+// LoadHistory reads previous lines from path and appends accepted lines to
+// it from now on. A missing file is not an error.
+func (e *Editor) LoadHistory(path string) error {
+	e.historyPath = path
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > e.maxHistory {
+		e.history = e.history[len(e.history)-e.maxHistory:]
+		return e.rewriteHistory()
+	}
+	return nil
+}
+
+func (e *Editor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > e.maxHistory {
+		e.history = e.history[1:]
+	}
+	if e.historyPath == "" {
+		return
+	}
+	f, err := os.OpenFile(e.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// SaveHistory rewrites the history file with the lines kept in memory,
+// which trims it to the history limit. It does nothing if LoadHistory was
+// never called.
+func (e *Editor) SaveHistory() error {
+	if e.historyPath == "" {
+		return nil
+	}
+	return e.rewriteHistory()
+}
+
+func (e *Editor) rewriteHistory() error {
+	return os.WriteFile(e.historyPath, []byte(strings.Join(e.history, "\n")+"\n"), 0o600)
+}
+
+// ReadLine shows prompt and returns the next line without its newline. It
+// returns io.EOF at the end of input or on Ctrl-D on an empty line, and
+// ErrInterrupt on Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if !e.Interactive() {
+		return e.readPlain(prompt)
+	}
+	restore, err := makeRaw(e.in.Fd())
+	if err != nil {
+		return e.readPlain(prompt)
+	}
+	defer restore()
+	return e.edit(prompt)
+}
+
+func (e *Editor) readPlain(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	line, err := e.reader.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	e.addHistory(line)
+	return line, nil
+}
+
+// lineState is the line being edited and the cursor position in it.
+type lineState struct {
+	buf []rune
+	pos int
+}
+
+func (s *lineState) insert(r ...rune) {
+	s.buf = append(s.buf[:s.pos], append(r, s.buf[s.pos:]...)...)
+	s.pos += len(r)
+}
+
+func (s *lineState) set(line string) {
+	s.buf = []rune(line)
+	s.pos = len(s.buf)
+}
+
+// edit runs the key loop. The terminal must already be in raw mode.
+func (e *Editor) edit(prompt string) (string, error) {
+	s := &lineState{}
+	// Browsing history works on a copy with the new line at the end so that
+	// edits to recalled lines do not change the history itself.
+	lines := append(append([]string{}, e.history...), "")
+	index := len(lines) - 1
+	lastWasTab := false
+	e.refresh(prompt, s)
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		tab := false
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			line := string(s.buf)
+			e.addHistory(line)
+			return line, nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupt
+		case 4: // Ctrl-D
+			if len(s.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			s.deleteForward()
+		case 1: // Ctrl-A
+			s.pos = 0
+		case 5: // Ctrl-E
+			s.pos = len(s.buf)
+		case 2: // Ctrl-B
+			s.left()
+		case 6: // Ctrl-F
+			s.right()
+		case 11: // Ctrl-K
+			s.buf = s.buf[:s.pos]
+		case 21: // Ctrl-U
+			s.buf = s.buf[s.pos:]
+			s.pos = 0
+		case 23: // Ctrl-W
+			s.deleteWord()
+		case 12: // Ctrl-L
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case 127, 8: // Backspace
+			s.backspace()
+		case 16: // Ctrl-P
+			index = e.recall(lines, index, -1, s)
+		case 14: // Ctrl-N
+			index = e.recall(lines, index, 1, s)
+		case '\t':
+			tab = true
+			e.completeWord(s, lastWasTab)
+		case 27: // Escape sequence
+			switch e.readEscape() {
+			case "[A", "OA":
+				index = e.recall(lines, index, -1, s)
+			case "[B", "OB":
+				index = e.recall(lines, index, 1, s)
+			case "[C", "OC":
+				s.right()
+			case "[D", "OD":
+				s.left()
+			case "[H", "OH", "[1~", "[7~":
+				s.pos = 0
+			case "[F", "OF", "[4~", "[8~":
+				s.pos = len(s.buf)
+			case "[3~":
+				s.deleteForward()
+			}
+		default:
+			if unicode.IsPrint(r) {
+				s.insert(r)
+			}
+		}
+		if index == len(lines)-1 {
+			lines[index] = string(s.buf)
+		}
+		lastWasTab = tab
+		e.refresh(prompt, s)
+	}
+}
+
+// readEscape reads the rest of an escape sequence after ESC, such as "[A"
+// for the up arrow or "[3~" for delete.
+func (e *Editor) readEscape() string {
+	first, _, err := e.reader.ReadRune()
+	if err != nil || (first != '[' && first != 'O') {
+		return ""
+	}
+	seq := []rune{first}
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return ""
+		}
+		seq = append(seq, r)
+		if r >= 0x40 && r <= 0x7e {
+			return string(seq)
+		}
+	}
+}
+
+// recall replaces the line with the history entry delta steps away.
+func (e *Editor) recall(lines []string, index, delta int, s *lineState) int {
+	next := index + delta
+	if next < 0 || next >= len(lines) {
+		return index
+	}
+	s.set(lines[next])
+	return next
+}
+
+func (s *lineState) left() {
+	if s.pos > 0 {
+		s.pos--
+	}
+}
+
+func (s *lineState) right() {
+	if s.pos < len(s.buf) {
+		s.pos++
+	}
+}
+
+func (s *lineState) backspace() {
+	if s.pos == 0 {
+		return
+	}
+	s.buf = append(s.buf[:s.pos-1], s.buf[s.pos:]...)
+	s.pos--
+}
+
+func (s *lineState) deleteForward() {
+	if s.pos < len(s.buf) {
+		s.buf = append(s.buf[:s.pos], s.buf[s.pos+1:]...)
+	}
+}
+
+func (s *lineState) deleteWord() {
+	start := s.pos
+	for start > 0 && s.buf[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && s.buf[start-1] != ' ' {
+		start--
+	}
+	s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+	s.pos = start
+}
+
+// completeWord completes the word before the cursor. A unique candidate is
+// inserted in full followed by a space; otherwise the longest common prefix
+// is inserted, and a second Tab lists the candidates.
+func (e *Editor) completeWord(s *lineState, list bool) {
+	if e.complete == nil {
+		return
+	}
+	line := string(s.buf[:s.pos])
+	word := line[strings.LastIndex(line, " ")+1:]
+	var matches []string
+	for _, candidate := range e.complete(line) {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return
+	case 1:
+		s.insert([]rune(matches[0][len(word):] + " ")...)
+		return
+	}
+	prefix := commonPrefix(matches)
+	if len(prefix) > len(word) {
+		s.insert([]rune(prefix[len(word):])...)
+		return
+	}
+	if list {
+		e.listCandidates(matches)
+	}
+}
+
+func (e *Editor) listCandidates(matches []string) {
+	const maxShown = 100
+	sort.Strings(matches)
+	shown := matches
+	if len(shown) > maxShown {
+		shown = shown[:maxShown]
+	}
+	fmt.Fprint(e.out, "\r\n", strings.Join(shown, "  "))
+	if len(matches) > maxShown {
+		fmt.Fprintf(e.out, "  ... and %d more", len(matches)-maxShown)
+	}
+	fmt.Fprint(e.out, "\r\n")
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// refresh redraws the prompt and line and puts the cursor in place.
+func (e *Editor) refresh(prompt string, s *lineState) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(s.buf))
+	if back := len(s.buf) - s.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,117 @@
+package lineedit
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEdit(t *testing.T) {
+	commands := []string{"catch", "cache", "explore", "exit"}
+	complete := func(line string) []string {
+		if !strings.Contains(line, " ") {
+			return commands
+		}
+		return []string{"pikachu", "pidgey"}
+	}
+	cases := []struct {
+		name     string
+		history  []string
+		keys     string
+		expected string
+	}{
+		{name: "typing", keys: "map\r", expected: "map"},
+		{name: "backspace", keys: "mapx\x7f\r", expected: "map"},
+		{name: "cursor movement", keys: "atch\x1b[D\x1b[D\x1b[D\x1b[Dc\x05!\r", expected: "catch!"},
+		{name: "kill to start", keys: "junk\x15help\r", expected: "help"},
+		{name: "delete word", keys: "catch pidgey\x17pikachu\r", expected: "catch pikachu"},
+		{name: "history up", history: []string{"map", "help"}, keys: "\x1b[A\x1b[A\r", expected: "map"},
+		{name: "history down", history: []string{"map", "help"}, keys: "new\x1b[A\x1b[B\r", expected: "new"},
+		{name: "unique completion", keys: "exp\t\r", expected: "explore "},
+		{name: "common prefix", keys: "ca\tt\t\r", expected: "catch "},
+		{name: "argument completion", keys: "catch pik\t\r", expected: "catch pikachu "},
+		{name: "no completion", keys: "xyz\t\r", expected: "xyz"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := newEditor(strings.NewReader(c.keys), io.Discard)
+			e.history = c.history
+			e.SetCompleter(complete)
+			line, err := e.edit("> ")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if line != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, line)
+			}
+		})
+	}
+}
+
+func TestEditControlKeys(t *testing.T) {
+	e := newEditor(strings.NewReader("\x04"), io.Discard)
+	if _, err := e.edit("> "); !errors.Is(err, io.EOF) {
+		t.Errorf("expected Ctrl-D on an empty line to be EOF, got %v", err)
+	}
+	e = newEditor(strings.NewReader("map\x03"), io.Discard)
+	if _, err := e.edit("> "); !errors.Is(err, ErrInterrupt) {
+		t.Errorf("expected Ctrl-C to interrupt, got %v", err)
+	}
+}
+
+func TestHistoryPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	e := newEditor(strings.NewReader("map\nmap\nhelp\n"), io.Discard)
+	if err := e.LoadHistory(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := e.ReadLine("> "); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := e.ReadLine("> "); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF at the end of input, got %v", err)
+	}
+
+	restarted := newEditor(strings.NewReader(""), io.Discard)
+	if err := restarted.LoadHistory(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"map", "help"}
+	if strings.Join(restarted.history, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected history %v, got %v", expected, restarted.history)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected history file: %v", err)
+	}
+}
+
+func TestSaveHistoryTrims(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(path, []byte("map\nmapb\nhelp\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	e := newEditor(strings.NewReader("bag\n"), io.Discard)
+	e.maxHistory = 2
+	if err := e.LoadHistory(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := e.ReadLine("> "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := e.SaveHistory(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != "help\nbag\n" {
+		t.Errorf("expected the history file to keep the last 2 lines, got %q", raw)
+	}
+}
@@ -0,0 +1,194 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultBaseURL = "https://pokeapi.co/api/v2"
+
+// Cache is the storage a Client reads through before going to the network.
+// *pokecache.Cache satisfies it.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Add(key string, value []byte)
+}
+
+// RevalidatingCache is implemented by caches that keep expired entries with
+// their HTTP validators, letting the Client refresh them with a conditional
+// request. *pokecache.Cache satisfies it.
+type RevalidatingCache interface {
+	Cache
+	GetStale(key string) (value []byte, etag, lastModified string, ok bool)
+	AddValidated(key string, value []byte, etag, lastModified string)
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	cache      Cache
+	flights    flightGroup
+	retry      RetryPolicy
+	limiter    *rateLimiter
+}
+
+type Option func(*Client)
+
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		retry:      DefaultRetryPolicy,
+		limiter:    newRateLimiter(10, 20),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// get fetches url, going through the cache if one is configured, and decodes
+// the JSON body into v.
+func (c *Client) get(ctx context.Context, url string, v any) error {
+	raw, err := c.fetch(ctx, url)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("Failed to parse data from PokeAPI: %v", err)
+	}
+	return nil
+}
+
+func (c *Client) fetch(ctx context.Context, url string) ([]byte, error) {
+	if c.cache != nil {
+		if v, ok := c.cache.Get(url); ok {
+			return v, nil
+		}
+	}
+	return c.flights.do(ctx, url, func(ctx context.Context) ([]byte, error) {
+		return c.download(ctx, url)
+	})
+}
+
+// download requests url, retrying according to the client's RetryPolicy.
+func (c *Client) download(ctx context.Context, url string) ([]byte, error) {
+	attempts := c.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay, retry := retryDelay(err)
+			if !retry || (c.retry.MaxDelay > 0 && delay > c.retry.MaxDelay) {
+				return nil, err
+			}
+			if delay == 0 {
+				delay = c.retry.backoff(attempt - 1)
+			}
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+		if c.limiter != nil {
+			if err := c.limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		var raw []byte
+		raw, err = c.request(ctx, url)
+		if err == nil || ctx.Err() != nil {
+			return raw, err
+		}
+	}
+	return nil, err
+}
+
+// request performs a single request for url and stores a successful body in
+// the cache. If the cache still holds an expired copy with
+// validators, the request is made conditional and a 304 reuses that copy.
+func (c *Client) request(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch data from PokeAPI: %v", err)
+	}
+	rc, revalidating := c.cache.(RevalidatingCache)
+	var stale []byte
+	var etag, lastModified string
+	if revalidating {
+		var ok bool
+		stale, etag, lastModified, ok = rc.GetStale(url)
+		if !ok {
+			stale = nil
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("Failed to fetch data from PokeAPI: %v", err)
+	}
+	defer res.Body.Close()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if res.StatusCode == http.StatusNotModified && stale != nil {
+		if v := res.Header.Get("ETag"); v != "" {
+			etag = v
+		}
+		if v := res.Header.Get("Last-Modified"); v != "" {
+			lastModified = v
+		}
+		rc.AddValidated(url, stale, etag, lastModified)
+		return stale, nil
+	}
+	// Only successful bodies are cached; an error page must not shadow the
+	// real resource once it becomes available.
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(res.Body)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read response body: %v", err)
+	}
+	if revalidating {
+		rc.AddValidated(url, raw, res.Header.Get("ETag"), res.Header.Get("Last-Modified"))
+	} else if c.cache != nil {
+		c.cache.Add(url, raw)
+	}
+	return raw, nil
+}
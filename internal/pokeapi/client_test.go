@@ -0,0 +1,273 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UUest/pokecli/internal/pokecache"
+)
+
+func TestGetPokemon(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/pokemon/pikachu" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112}`)
+	}))
+	defer srv.Close()
+
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	client := NewClient(WithBaseURL(srv.URL), WithCache(cache))
+	for i := 0; i < 2; i++ {
+		pkm, err := client.GetPokemon(context.Background(), "pikachu")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pkm.Name != "pikachu" || pkm.BaseExperience != 112 {
+			t.Errorf("unexpected pokemon %v (%v)", pkm.Name, pkm.BaseExperience)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %v", requests)
+	}
+}
+
+func TestListLocationAreas(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/location-area" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"count": 2, "next": "%s/location-area?offset=20", "previous": null, "results": [{"name": "canalave-city-area"}, {"name": "eterna-city-area"}]}`, srv.URL)
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	list, err := client.ListLocationAreas(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Results) != 2 || list.Results[0].Name != "canalave-city-area" {
+		t.Errorf("unexpected results %v", list.Results)
+	}
+	if list.Next != srv.URL+"/location-area?offset=20" {
+		t.Errorf("unexpected next url %v", list.Next)
+	}
+	if list.Previous != "" {
+		t.Errorf("expected no previous url, got %v", list.Previous)
+	}
+}
+
+func TestErrorResponsesNotCached(t *testing.T) {
+	cases := []struct {
+		status int
+		check  func(error) bool
+	}{
+		{
+			status: http.StatusNotFound,
+			check:  func(err error) bool { var e *NotFoundError; return errors.As(err, &e) },
+		},
+		{
+			status: http.StatusTooManyRequests,
+			check: func(err error) bool {
+				var e *RateLimitError
+				return errors.As(err, &e) && e.RetryAfter == 2*time.Second
+			},
+		},
+		{
+			status: http.StatusBadGateway,
+			check:  func(err error) bool { var e *ServerError; return errors.As(err, &e) && e.StatusCode == 502 },
+		},
+		{
+			status: http.StatusTeapot,
+			check:  func(err error) bool { var e *StatusError; return errors.As(err, &e) },
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(c.status)
+				fmt.Fprint(w, "Not Found")
+			}))
+			defer srv.Close()
+
+			cache := pokecache.NewCache(time.Minute)
+			defer cache.Close()
+			client := NewClient(WithBaseURL(srv.URL), WithCache(cache), WithRetryPolicy(NoRetries))
+			_, err := client.GetPokemon(context.Background(), "pikachuu")
+			if !c.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+			if _, ok := cache.Get(srv.URL + "/pokemon/pikachuu"); ok {
+				t.Errorf("expected error response not to be cached")
+			}
+		})
+	}
+}
+
+func TestConcurrentFetchesCoalesce(t *testing.T) {
+	const callers = 10
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		fmt.Fprint(w, `{"name": "canalave-city-area"}`)
+	}))
+	defer srv.Close()
+
+	// Callers that arrive after the flight has landed are served by the cache.
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	client := NewClient(WithBaseURL(srv.URL), WithCache(cache))
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			area, err := client.GetLocationArea(context.Background(), "canalave-city-area")
+			if err == nil && area.Name != "canalave-city-area" {
+				err = fmt.Errorf("unexpected area %v", area.Name)
+			}
+			errs <- err
+		}()
+	}
+	for requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %v", n)
+	}
+}
+
+func TestConditionalRevalidation(t *testing.T) {
+	etag := `"v1"`
+	body := `{"name": "pikachu", "base_experience": 112}`
+	var full, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	const ttl = 5 * time.Millisecond
+	cache := pokecache.NewCache(time.Minute, pokecache.WithDiskStore(t.TempDir(), ttl))
+	defer cache.Close()
+	client := NewClient(WithBaseURL(srv.URL), WithCache(cache))
+
+	expectExperience := func(expected int) {
+		t.Helper()
+		pkm, err := client.GetPokemon(context.Background(), "pikachu")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pkm.BaseExperience != expected {
+			t.Errorf("expected base experience %v, got %v", expected, pkm.BaseExperience)
+		}
+	}
+
+	expectExperience(112)
+	time.Sleep(2 * ttl)
+	expectExperience(112)
+	if full != 1 || notModified != 1 {
+		t.Errorf("expected 1 full and 1 conditional response, got %v and %v", full, notModified)
+	}
+
+	etag = `"v2"`
+	body = `{"name": "pikachu", "base_experience": 113}`
+	time.Sleep(2 * ttl)
+	expectExperience(113)
+	if full != 2 {
+		t.Errorf("expected changed resource to be downloaded again, got %v full responses", full)
+	}
+}
+
+func TestCancelledFetch(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := NewClient(WithBaseURL(srv.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.GetPokemon(ctx, "pikachu")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestCancelledLeaderDoesNotFailFollowers(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		fmt.Fprint(w, `{"name": "pikachu"}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := client.GetPokemon(leaderCtx, "pikachu")
+		leaderErr <- err
+	}()
+	for requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	followerErr := make(chan error, 1)
+	go func() {
+		pkm, err := client.GetPokemon(context.Background(), "pikachu")
+		if err == nil && pkm.Name != "pikachu" {
+			err = fmt.Errorf("unexpected Pokemon %v", pkm.Name)
+		}
+		followerErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancelLeader()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the leader to be cancelled, got %v", err)
+	}
+	close(release)
+	if err := <-followerErr; err != nil {
+		t.Errorf("expected the follower to get the result, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %v", n)
+	}
+}
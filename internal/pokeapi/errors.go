@@ -0,0 +1,88 @@
+package pokeapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// NotFoundError is returned when PokeAPI has no resource at URL, usually
+// because the name was misspelled.
+type NotFoundError struct {
+	URL string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("PokeAPI has no resource at %s", e.URL)
+}
+
+// RateLimitError is returned on a 429 response. RetryAfter is zero when the
+// server did not say how long to wait.
+type RateLimitError struct {
+	URL        string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("PokeAPI rate limit reached, retry in %v", e.RetryAfter)
+	}
+	return "PokeAPI rate limit reached"
+}
+
+// ServerError is returned on any 5xx response. RetryAfter is set when the
+// server said how long to wait, typically on a 503.
+type ServerError struct {
+	URL        string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("PokeAPI server error: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// StatusError is returned for any other non-200 response.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected PokeAPI response: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// checkResponse maps a non-200 response to one of the error types above.
+func checkResponse(res *http.Response) error {
+	url := res.Request.URL.String()
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return nil
+	case res.StatusCode == http.StatusNotFound:
+		return &NotFoundError{URL: url}
+	case res.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{URL: url, RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"))}
+	case res.StatusCode >= 500:
+		return &ServerError{URL: url, StatusCode: res.StatusCode, RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"))}
+	default:
+		return &StatusError{URL: url, StatusCode: res.StatusCode}
+	}
+}
+
+// parseRetryAfter accepts both forms of the Retry-After header: a number of
+// seconds or an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
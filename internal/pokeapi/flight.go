@@ -0,0 +1,76 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup deduplicates concurrent fetches of the same URL: the first
+// caller starts the request and everyone else arriving while it is in
+// flight waits for and shares its result, like x/sync/singleflight.
+//
+// The request runs detached from any one caller's cancellation, so a caller
+// that gives up does not fail the others. A caller whose own context ends
+// stops waiting and returns its context's error; once every caller has left,
+// the request itself is cancelled.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done    chan struct{}
+	val     []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+		go func() {
+			call.val, call.err = fn(callCtx)
+			cancel()
+			g.forget(key, call)
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody wants the result any more. A caller arriving later
+			// starts a fresh request instead of joining the cancelled one.
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			call.cancel()
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// forget removes call from the group if it is still the one in flight for
+// key.
+func (g *flightGroup) forget(key string, call *flightCall) {
+	g.mu.Lock()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+}
@@ -0,0 +1,45 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type Item struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Cost     int    `json:"cost"`
+	Category struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"category"`
+	EffectEntries []struct {
+		Effect      string `json:"effect"`
+		ShortEffect string `json:"short_effect"`
+		Language    struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+	} `json:"effect_entries"`
+}
+
+// ShortEffect returns the English one-line description of the item, or an
+// empty string if PokeAPI has none.
+func (i Item) ShortEffect() string {
+	for _, entry := range i.EffectEntries {
+		if entry.Language.Name == "en" {
+			return entry.ShortEffect
+		}
+	}
+	return ""
+}
+
+func (c *Client) GetItem(ctx context.Context, name string) (Item, error) {
+	endpoint := fmt.Sprintf("%s/item/%s", c.baseURL, url.PathEscape(name))
+	item := Item{}
+	if err := c.get(ctx, endpoint, &item); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
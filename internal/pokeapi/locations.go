@@ -0,0 +1,98 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type LocationAreaList struct {
+	Count    int    `json:"count"`
+	Next     string `json:"next"`
+	Previous string `json:"previous"`
+	Results  []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"results"`
+}
+
+type LocationArea struct {
+	EncounterMethodRates []struct {
+		EncounterMethod struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"encounter_method"`
+		VersionDetails []struct {
+			Rate    int `json:"rate"`
+			Version struct {
+				Name string `json:"name"`
+				URL  string `json:"url"`
+			} `json:"version"`
+		} `json:"version_details"`
+	} `json:"encounter_method_rates"`
+	GameIndex int `json:"game_index"`
+	ID        int `json:"id"`
+	Location  struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"location"`
+	Name  string `json:"name"`
+	Names []struct {
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		Name string `json:"name"`
+	} `json:"names"`
+	PokemonEncounters []struct {
+		Pokemon struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon"`
+		VersionDetails []struct {
+			EncounterDetails []struct {
+				Chance          int           `json:"chance"`
+				ConditionValues []interface{} `json:"condition_values"`
+				MaxLevel        int           `json:"max_level"`
+				Method          struct {
+					Name string `json:"name"`
+					URL  string `json:"url"`
+				} `json:"method"`
+				MinLevel int `json:"min_level"`
+			} `json:"encounter_details"`
+			MaxChance int `json:"max_chance"`
+			Version   struct {
+				Name string `json:"name"`
+				URL  string `json:"url"`
+			} `json:"version"`
+		} `json:"version_details"`
+	} `json:"pokemon_encounters"`
+}
+
+// ListLocationAreas returns one page of location areas. An empty pageURL
+// fetches the first page; otherwise pass the Next or Previous URL of a page
+// returned earlier.
+func (c *Client) ListLocationAreas(ctx context.Context, pageURL string) (LocationAreaList, error) {
+	if pageURL == "" {
+		pageURL = c.baseURL + "/location-area?limit=20&offset=0"
+	}
+	list := LocationAreaList{}
+	if err := c.get(ctx, pageURL, &list); err != nil {
+		return LocationAreaList{}, err
+	}
+	return list, nil
+}
+
+func (c *Client) GetLocationArea(ctx context.Context, name string) (LocationArea, error) {
+	endpoint := fmt.Sprintf("%s/location-area/%s", c.baseURL, url.PathEscape(name))
+	area := LocationArea{}
+	if err := c.get(ctx, endpoint, &area); err != nil {
+		return LocationArea{}, err
+	}
+	return area, nil
+}
+
+// ListAllLocationAreas returns the names of every location area in one list.
+func (c *Client) ListAllLocationAreas(ctx context.Context) (NamedResourceList, error) {
+	return c.list(ctx, "location-area")
+}
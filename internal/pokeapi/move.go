@@ -0,0 +1,35 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Move is an attack. Power and Accuracy are nil for moves that do no direct
+// damage or never miss.
+type Move struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Power       *int   `json:"power"`
+	Accuracy    *int   `json:"accuracy"`
+	PP          int    `json:"pp"`
+	Priority    int    `json:"priority"`
+	DamageClass struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"damage_class"`
+	Type struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"type"`
+}
+
+func (c *Client) GetMove(ctx context.Context, name string) (Move, error) {
+	endpoint := fmt.Sprintf("%s/move/%s", c.baseURL, url.PathEscape(name))
+	move := Move{}
+	if err := c.get(ctx, endpoint, &move); err != nil {
+		return Move{}, err
+	}
+	return move, nil
+}
@@ -0,0 +1,36 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Nature raises one stat by 10% and lowers another by 10%. Neutral natures
+// name the same stat for both, or none at all.
+type Nature struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	IncreasedStat *struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"increased_stat"`
+	DecreasedStat *struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"decreased_stat"`
+}
+
+// ListNatures returns the 25 natures a Pokemon can have.
+func (c *Client) ListNatures(ctx context.Context) (NamedResourceList, error) {
+	return c.list(ctx, "nature")
+}
+
+func (c *Client) GetNature(ctx context.Context, name string) (Nature, error) {
+	endpoint := fmt.Sprintf("%s/nature/%s", c.baseURL, url.PathEscape(name))
+	nature := Nature{}
+	if err := c.get(ctx, endpoint, &nature); err != nil {
+		return Nature{}, err
+	}
+	return nature, nil
+}
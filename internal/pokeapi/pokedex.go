@@ -0,0 +1,103 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// NamedResourceList is the generic paged list PokeAPI returns for most
+// collection endpoints.
+type NamedResourceList struct {
+	Count    int    `json:"count"`
+	Next     string `json:"next"`
+	Previous string `json:"previous"`
+	Results  []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"results"`
+}
+
+type Generation struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	MainRegion struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"main_region"`
+	PokemonSpecies []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"pokemon_species"`
+}
+
+type Region struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Pokedexes []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"pokedexes"`
+}
+
+type Pokedex struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	IsMainSeries   bool   `json:"is_main_series"`
+	PokemonEntries []struct {
+		EntryNumber    int `json:"entry_number"`
+		PokemonSpecies struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon_species"`
+	} `json:"pokemon_entries"`
+	Region struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"region"`
+}
+
+// list fetches every entry of a collection endpoint in one request.
+// PokeAPI's largest collections have a little over a thousand entries.
+func (c *Client) list(ctx context.Context, resource string) (NamedResourceList, error) {
+	list := NamedResourceList{}
+	if err := c.get(ctx, fmt.Sprintf("%s/%s?limit=2000", c.baseURL, resource), &list); err != nil {
+		return NamedResourceList{}, err
+	}
+	return list, nil
+}
+
+func (c *Client) ListGenerations(ctx context.Context) (NamedResourceList, error) {
+	return c.list(ctx, "generation")
+}
+
+func (c *Client) GetGeneration(ctx context.Context, name string) (Generation, error) {
+	endpoint := fmt.Sprintf("%s/generation/%s", c.baseURL, url.PathEscape(name))
+	generation := Generation{}
+	if err := c.get(ctx, endpoint, &generation); err != nil {
+		return Generation{}, err
+	}
+	return generation, nil
+}
+
+func (c *Client) ListRegions(ctx context.Context) (NamedResourceList, error) {
+	return c.list(ctx, "region")
+}
+
+func (c *Client) GetRegion(ctx context.Context, name string) (Region, error) {
+	endpoint := fmt.Sprintf("%s/region/%s", c.baseURL, url.PathEscape(name))
+	region := Region{}
+	if err := c.get(ctx, endpoint, &region); err != nil {
+		return Region{}, err
+	}
+	return region, nil
+}
+
+func (c *Client) GetPokedex(ctx context.Context, name string) (Pokedex, error) {
+	endpoint := fmt.Sprintf("%s/pokedex/%s", c.baseURL, url.PathEscape(name))
+	pokedex := Pokedex{}
+	if err := c.get(ctx, endpoint, &pokedex); err != nil {
+		return Pokedex{}, err
+	}
+	return pokedex, nil
+}
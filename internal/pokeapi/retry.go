@@ -0,0 +1,141 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how often a failed request is retried. Transport
+// errors, 429 and 5xx responses are retried; other responses are final.
+// Between attempts the client waits a random duration between zero and
+// BaseDelay*2^attempt, capped at MaxDelay ("full jitter"), unless the server
+// sent a Retry-After header, which is honored instead. A Retry-After longer
+// than MaxDelay is not waited out: the RateLimitError or ServerError is
+// returned right away. A zero MaxDelay means no cap.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// NoRetries makes every request a single attempt.
+var NoRetries = RetryPolicy{MaxAttempts: 1}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithRateLimit limits the client to perSecond requests on average, allowing
+// bursts of up to burst requests. A zero rate disables limiting.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *Client) {
+		if perSecond <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newRateLimiter(perSecond, burst)
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	ceiling := p.BaseDelay << attempt
+	if ceiling <= 0 || (p.MaxDelay > 0 && ceiling > p.MaxDelay) {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		ceiling = p.BaseDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// retryDelay reports whether err is worth retrying and how long the server
+// asked us to wait first, if it did.
+func retryDelay(err error) (time.Duration, bool) {
+	var rateLimited *RateLimitError
+	var serverErr *ServerError
+	var notFound *NotFoundError
+	var statusErr *StatusError
+	switch {
+	case errors.As(err, &rateLimited):
+		return rateLimited.RetryAfter, true
+	case errors.As(err, &serverErr):
+		return serverErr.RetryAfter, true
+	case errors.As(err, &notFound), errors.As(err, &statusErr):
+		return 0, false
+	default:
+		return 0, true
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimiter is a token bucket: it holds up to burst tokens, refills at
+// rate tokens per second and every request takes one.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		missing := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+		if err := sleep(ctx, missing); err != nil {
+			return err
+		}
+	}
+}
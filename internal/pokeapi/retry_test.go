@@ -0,0 +1,184 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var fastRetries = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    5 * time.Millisecond,
+}
+
+// flakyServer answers with the given statuses in order, then with a valid
+// Pokemon, counting the requests it receives.
+func flakyServer(t *testing.T, retryAfter string, statuses ...int) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= len(statuses) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(statuses[requests-1])
+			return
+		}
+		fmt.Fprint(w, `{"name": "pikachu"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestRetry(t *testing.T) {
+	cases := []struct {
+		statuses []int
+		policy   RetryPolicy
+		requests int
+		wantErr  bool
+	}{
+		{
+			statuses: []int{http.StatusInternalServerError, http.StatusBadGateway},
+			policy:   fastRetries,
+			requests: 3,
+		},
+		{
+			statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			policy:   fastRetries,
+			requests: 4,
+			wantErr:  true,
+		},
+		{
+			statuses: []int{http.StatusNotFound},
+			policy:   fastRetries,
+			requests: 1,
+			wantErr:  true,
+		},
+		{
+			statuses: []int{http.StatusInternalServerError},
+			policy:   NoRetries,
+			requests: 1,
+			wantErr:  true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			srv, requests := flakyServer(t, "", c.statuses...)
+			client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(c.policy))
+			_, err := client.GetPokemon(context.Background(), "pikachu")
+			if (err != nil) != c.wantErr {
+				t.Errorf("expected error %v, got %v", c.wantErr, err)
+			}
+			if *requests != c.requests {
+				t.Errorf("expected %v requests, got %v", c.requests, *requests)
+			}
+		})
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	patient := RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}
+	cases := []struct {
+		name       string
+		retryAfter string
+		status     int
+		policy     RetryPolicy
+		minElapsed time.Duration
+		maxElapsed time.Duration
+		requests   int
+		wantErr    error
+	}{
+		{
+			name:       "waits for the server",
+			retryAfter: "1",
+			status:     http.StatusTooManyRequests,
+			policy:     patient,
+			minElapsed: time.Second,
+			maxElapsed: 2 * time.Second,
+			requests:   2,
+		},
+		{
+			name:       "gives up on a delay longer than MaxDelay",
+			retryAfter: "86400",
+			status:     http.StatusTooManyRequests,
+			policy:     patient,
+			maxElapsed: time.Second,
+			requests:   1,
+			wantErr:    &RateLimitError{},
+		},
+		{
+			name:       "gives up on a server error with a long delay",
+			retryAfter: "10",
+			status:     http.StatusServiceUnavailable,
+			policy:     fastRetries,
+			maxElapsed: time.Second,
+			requests:   1,
+			wantErr:    &ServerError{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv, requests := flakyServer(t, c.retryAfter, c.status)
+			client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(c.policy))
+			start := time.Now()
+			_, err := client.GetPokemon(context.Background(), "pikachu")
+			elapsed := time.Since(start)
+			switch want := c.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			case *RateLimitError:
+				if !errors.As(err, &want) {
+					t.Errorf("expected a RateLimitError, got %v", err)
+				}
+			case *ServerError:
+				if !errors.As(err, &want) {
+					t.Errorf("expected a ServerError, got %v", err)
+				}
+			}
+			if elapsed < c.minElapsed || elapsed > c.maxElapsed {
+				t.Errorf("expected to take between %v and %v, took %v", c.minElapsed, c.maxElapsed, elapsed)
+			}
+			if *requests != c.requests {
+				t.Errorf("expected %v requests, got %v", c.requests, *requests)
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	srv, _ := flakyServer(t, "10", http.StatusServiceUnavailable)
+	patient := RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: time.Minute}
+	client := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(patient))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetPokemon(ctx, "pikachu"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	srv, requests := flakyServer(t, "")
+	client := NewClient(WithBaseURL(srv.URL), WithRateLimit(20, 1))
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetPokemon(context.Background(), fmt.Sprintf("pikachu-%d", i)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected 3 requests at 20/s with no burst to take at least 100ms, took %v", elapsed)
+	}
+	if *requests != 3 {
+		t.Errorf("expected 3 requests, got %v", *requests)
+	}
+}
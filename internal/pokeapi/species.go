@@ -0,0 +1,43 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type PokemonSpecies struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Order         int    `json:"order"`
+	GenderRate    int    `json:"gender_rate"`
+	CaptureRate   int    `json:"capture_rate"`
+	BaseHappiness int    `json:"base_happiness"`
+	IsBaby        bool   `json:"is_baby"`
+	IsLegendary   bool   `json:"is_legendary"`
+	IsMythical    bool   `json:"is_mythical"`
+	GrowthRate    struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"growth_rate"`
+	PokedexNumbers []struct {
+		EntryNumber int `json:"entry_number"`
+		Pokedex     struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokedex"`
+	} `json:"pokedex_numbers"`
+	Generation struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"generation"`
+}
+
+func (c *Client) GetPokemonSpecies(ctx context.Context, name string) (PokemonSpecies, error) {
+	endpoint := fmt.Sprintf("%s/pokemon-species/%s", c.baseURL, url.PathEscape(name))
+	species := PokemonSpecies{}
+	if err := c.get(ctx, endpoint, &species); err != nil {
+		return PokemonSpecies{}, err
+	}
+	return species, nil
+}
@@ -0,0 +1,54 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Type is an elemental type and how it fares against the others.
+type Type struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	DamageRelations struct {
+		DoubleDamageTo []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"double_damage_to"`
+		HalfDamageTo []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"half_damage_to"`
+		NoDamageTo []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"no_damage_to"`
+		DoubleDamageFrom []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"double_damage_from"`
+		HalfDamageFrom []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"half_damage_from"`
+		NoDamageFrom []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"no_damage_from"`
+	} `json:"damage_relations"`
+}
+
+// ListTypes returns every type, including the "unknown" and "shadow" types
+// that no Pokemon has.
+func (c *Client) ListTypes(ctx context.Context) (NamedResourceList, error) {
+	return c.list(ctx, "type")
+}
+
+func (c *Client) GetType(ctx context.Context, name string) (Type, error) {
+	endpoint := fmt.Sprintf("%s/type/%s", c.baseURL, url.PathEscape(name))
+	t := Type{}
+	if err := c.get(ctx, endpoint, &t); err != nil {
+		return Type{}, err
+	}
+	return t, nil
+}
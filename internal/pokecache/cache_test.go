@@ -25,6 +25,7 @@ func TestAddGet(t *testing.T) {
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
 			cache := NewCache(interval)
+			defer cache.Close()
 			cache.Add(c.key, c.val)
 			val, ok := cache.Get(c.key)
 			if !ok {
@@ -43,6 +44,7 @@ func TestReapLoop(t *testing.T) {
 	const baseTime = 5 * time.Millisecond
 	const waitTime = baseTime + 5*time.Millisecond
 	cache := NewCache(baseTime)
+	defer cache.Close()
 	cache.Add("https://example.com", []byte("testdata"))
 
 	_, ok := cache.Get("https://example.com")
@@ -59,3 +61,93 @@ func TestReapLoop(t *testing.T) {
 		return
 	}
 }
+
+func TestDiskStore(t *testing.T) {
+	const interval = 5 * time.Second
+	dir := t.TempDir()
+
+	cache := NewCache(interval, WithDiskStore(dir, time.Hour))
+	defer cache.Close()
+	cache.Add("https://example.com", []byte("testdata"))
+	cache.AddWithTTL("https://example.com/short", []byte("moretestdata"), time.Millisecond)
+
+	time.Sleep(5 * time.Millisecond)
+
+	restarted := NewCache(interval, WithDiskStore(dir, time.Hour))
+	defer restarted.Close()
+	val, ok := restarted.Get("https://example.com")
+	if !ok {
+		t.Errorf("expected to find key after restart")
+		return
+	}
+	if string(val) != "testdata" {
+		t.Errorf("expected to find value after restart")
+		return
+	}
+	if _, ok := restarted.Get("https://example.com/short"); ok {
+		t.Errorf("expected expired key to be gone after restart")
+		return
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	const interval = 5 * time.Second
+	cases := []struct {
+		opt     Option
+		evicted string
+		kept    []string
+	}{
+		{
+			opt:     WithMaxEntries(2),
+			evicted: "b",
+			kept:    []string{"a", "c"},
+		},
+		{
+			opt:     WithMaxBytes(2 * len("a1234")),
+			evicted: "b",
+			kept:    []string{"a", "c"},
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			cache := NewCache(interval, c.opt)
+			defer cache.Close()
+			cache.Add("a", []byte("1234"))
+			cache.Add("b", []byte("1234"))
+			cache.Get("a")
+			cache.Add("c", []byte("1234"))
+			if _, ok := cache.Get(c.evicted); ok {
+				t.Errorf("expected %s to be evicted", c.evicted)
+			}
+			for _, key := range c.kept {
+				if _, ok := cache.Get(key); !ok {
+					t.Errorf("expected to find %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	cache := NewCache(5*time.Second, WithMaxEntries(1))
+	defer cache.Close()
+	cache.Add("a", []byte("1234"))
+	cache.Get("a")
+	cache.Get("b")
+	cache.Add("b", []byte("12"))
+
+	stats := cache.Stats()
+	expected := Stats{Hits: 1, Misses: 1, Evictions: 1, Entries: 1, Bytes: len("b12")}
+	if stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+	if keys := cache.Keys(); len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("expected keys [b], got %v", keys)
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); stats != (Stats{}) {
+		t.Errorf("expected empty stats after clear, got %+v", stats)
+	}
+}
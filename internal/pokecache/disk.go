@@ -0,0 +1,132 @@
+package pokecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// diskStore keeps one JSON file per entry so that cached responses, and
+// their expiry times, survive between sessions.
+type diskStore struct {
+	dir string
+	ttl time.Duration
+}
+
+type diskEntry struct {
+	Key          string    `json:"key"`
+	Created      time.Time `json:"created"`
+	Expires      time.Time `json:"expires"`
+	Value        []byte    `json:"value"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+}
+
+func newDiskStore(dir string, ttl time.Duration) *diskStore {
+	return &diskStore{dir: dir, ttl: ttl}
+}
+
+func (d *diskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the entry for key if it has not expired. Expired entries are
+// deleted unless they carry validators, which keep them around for
+// revalidation.
+func (d *diskStore) get(key string) (diskEntry, bool) {
+	entry, ok := d.read(key)
+	if !ok {
+		return diskEntry{}, false
+	}
+	if time.Now().After(entry.Expires) {
+		if entry.ETag == "" && entry.LastModified == "" {
+			d.remove(key)
+		}
+		return diskEntry{}, false
+	}
+	return entry, true
+}
+
+// read returns the entry for key regardless of its expiry.
+func (d *diskStore) read(key string) (diskEntry, bool) {
+	raw, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return diskEntry{}, false
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(raw, &entry); err != nil || entry.Key != key {
+		return diskEntry{}, false
+	}
+	return entry, true
+}
+
+// put writes the entry to a temporary file first so that a crash never
+// leaves a half-written entry behind.
+func (d *diskStore) put(entry diskEntry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(d.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(entry.Key))
+}
+
+func (d *diskStore) remove(key string) {
+	os.Remove(d.path(key))
+}
+
+// keys lists the keys of all unexpired entries on disk.
+func (d *diskStore) keys() []string {
+	files, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(d.dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		var entry diskEntry
+		if err := json.Unmarshal(raw, &entry); err != nil || time.Now().After(entry.Expires) {
+			continue
+		}
+		keys = append(keys, entry.Key)
+	}
+	return keys
+}
+
+func (d *diskStore) clear() {
+	files, err := os.ReadDir(d.dir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".json") || strings.HasSuffix(f.Name(), ".tmp") {
+			os.Remove(filepath.Join(d.dir, f.Name()))
+		}
+	}
+}
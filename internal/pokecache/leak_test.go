@@ -0,0 +1,70 @@
+package pokecache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain fails the package if any test leaves a reap loop running, in the
+// spirit of goleak.VerifyTestMain.
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if code == 0 {
+		if err := waitForReapers(0); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// waitForReapers polls the goroutine dump until exactly want reap loops are
+// running, giving stopped goroutines a moment to unwind.
+func waitForReapers(want int) error {
+	deadline := time.Now().Add(time.Second)
+	for {
+		got := countReapers()
+		if got == want {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("expected %d reap loops running, found %d", want, got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func countReapers() int {
+	buf := make([]byte, 1<<20)
+	n := runtime.Stack(buf, true)
+	return strings.Count(string(buf[:n]), "pokecache.(*Cache).reapLoop")
+}
+
+func TestCloseStopsReaper(t *testing.T) {
+	cache := NewCache(time.Millisecond)
+	if err := waitForReapers(1); err != nil {
+		t.Fatal(err)
+	}
+	cache.Close()
+	cache.Close()
+	if err := waitForReapers(0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContextStopsReaper(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	NewCache(time.Millisecond, WithContext(ctx))
+	if err := waitForReapers(1); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := waitForReapers(0); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,53 +1,323 @@
 package pokecache
 
 import (
+	"container/list"
+	"context"
+	"sort"
 	"sync"
 	"time"
 )
 
 type Cache struct {
-	data map[string]cacheEntry
-	mu   sync.RWMutex
+	data       map[string]*list.Element
+	lru        *list.List
+	size       int
+	maxEntries int
+	maxBytes   int
+	disk       *diskStore
+	stats      Stats
+	ctx        context.Context
+	done       chan struct{}
+	closeOnce  sync.Once
+	mu         sync.Mutex
 }
 
 type cacheEntry struct {
-	created time.Time
-	value   []byte
+	key          string
+	created      time.Time
+	expires      time.Time
+	value        []byte
+	etag         string
+	lastModified string
 }
 
-func NewCache(interval time.Duration) *Cache {
+func (e *cacheEntry) size() int {
+	return len(e.key) + len(e.value)
+}
+
+func (e *cacheEntry) expired() bool {
+	return !e.expires.IsZero() && time.Now().After(e.expires)
+}
+
+// Stats is a snapshot of cache activity since the cache was created or last
+// cleared. Hits include DiskHits, entries that were read back from the disk
+// tier after missing in memory.
+type Stats struct {
+	Hits      int
+	DiskHits  int
+	Misses    int
+	Evictions int
+	Reaped    int
+	Entries   int
+	Bytes     int
+}
+
+type Option func(*Cache)
+
+// WithDiskStore adds a persistent tier under dir. Entries added to the cache
+// are also written there and stay valid for ttl, across restarts; memory
+// misses read through to it. Disk errors are ignored, the cache then behaves
+// as if the entry was never stored.
+func WithDiskStore(dir string, ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.disk = newDiskStore(dir, ttl)
+	}
+}
+
+// WithMaxEntries caps the number of entries held in memory. Once full, the
+// least recently used entry is evicted. Zero means no limit.
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// WithMaxBytes caps the combined size of keys and values held in memory,
+// evicting least recently used entries to stay under it. Zero means no
+// limit. A single value larger than the budget is not kept in memory at all.
+func WithMaxBytes(n int) Option {
+	return func(c *Cache) {
+		c.maxBytes = n
+	}
+}
+
+// WithContext stops the reap loop once ctx is done, as if Close had been
+// called.
+func WithContext(ctx context.Context) Option {
+	return func(c *Cache) {
+		c.ctx = ctx
+	}
+}
+
+func NewCache(interval time.Duration, opts ...Option) *Cache {
 	c := &Cache{
-		data: make(map[string]cacheEntry),
+		data: make(map[string]*list.Element),
+		lru:  list.New(),
+		ctx:  context.Background(),
+		done: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
 	go c.reapLoop(interval)
 	return c
 }
 
 func (c *Cache) Add(key string, value []byte) {
+	c.AddWithTTL(key, value, c.defaultTTL())
+}
+
+// AddWithTTL stores value with its own expiry. A zero ttl means the entry
+// only lives until the reap loop removes it from memory and is not
+// persisted.
+func (c *Cache) AddWithTTL(key string, value []byte, ttl time.Duration) {
+	c.add(key, value, ttl, "", "")
+}
+
+// AddValidated stores value together with the ETag and Last-Modified
+// validators of the response it came from. Once such an entry expires it is
+// still returned by GetStale so the caller can revalidate it instead of
+// downloading it again.
+func (c *Cache) AddValidated(key string, value []byte, etag, lastModified string) {
+	c.add(key, value, c.defaultTTL(), etag, lastModified)
+}
+
+func (c *Cache) defaultTTL() time.Duration {
+	if c.disk != nil {
+		return c.disk.ttl
+	}
+	return 0
+}
+
+func (c *Cache) add(key string, value []byte, ttl time.Duration, etag, lastModified string) {
+	now := time.Now()
+	entry := &cacheEntry{
+		key:          key,
+		created:      now,
+		value:        value,
+		etag:         etag,
+		lastModified: lastModified,
+	}
+	if ttl > 0 {
+		entry.expires = now.Add(ttl)
+	}
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.data[key] = cacheEntry{
-		created: time.Now(),
-		value:   value,
+	c.insert(entry)
+	c.mu.Unlock()
+
+	if c.disk != nil && ttl > 0 {
+		c.disk.put(diskEntry{
+			Key:          key,
+			Created:      now,
+			Expires:      entry.expires,
+			Value:        value,
+			ETag:         etag,
+			LastModified: lastModified,
+		})
+	}
+}
+
+// GetStale returns the entry for key even if it has expired, along with its
+// validators. Expired entries without validators are never returned.
+func (c *Cache) GetStale(key string) (value []byte, etag, lastModified string, ok bool) {
+	c.mu.Lock()
+	if el, ok := c.data[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		if entry.etag != "" || entry.lastModified != "" || !entry.expired() {
+			c.mu.Unlock()
+			return entry.value, entry.etag, entry.lastModified, true
+		}
 	}
+	c.mu.Unlock()
+	if c.disk == nil {
+		return nil, "", "", false
+	}
+	stored, ok := c.disk.read(key)
+	if !ok {
+		return nil, "", "", false
+	}
+	if time.Now().After(stored.Expires) && stored.ETag == "" && stored.LastModified == "" {
+		return nil, "", "", false
+	}
+	return stored.Value, stored.ETag, stored.LastModified, true
 }
 
 func (c *Cache) Get(key string) ([]byte, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	entry, ok := c.data[key]
-	return entry.value, ok
+	c.mu.Lock()
+	if el, ok := c.data[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		if !entry.expired() {
+			c.lru.MoveToFront(el)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return entry.value, true
+		}
+	}
+	c.mu.Unlock()
+	var stored diskEntry
+	ok := false
+	if c.disk != nil {
+		stored, ok = c.disk.get(key)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.stats.DiskHits++
+	c.insert(&cacheEntry{
+		key:          key,
+		created:      time.Now(),
+		expires:      stored.Expires,
+		value:        stored.Value,
+		etag:         stored.ETag,
+		lastModified: stored.LastModified,
+	})
+	return stored.Value, true
+}
+
+// insert adds entry as the most recently used one and evicts from the back
+// of the list until the limits hold again. c.mu must be held.
+func (c *Cache) insert(entry *cacheEntry) {
+	if el, ok := c.data[entry.key]; ok {
+		c.remove(el)
+	}
+	c.data[entry.key] = c.lru.PushFront(entry)
+	c.size += entry.size()
+	for c.overLimit() {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *Cache) overLimit() bool {
+	if c.lru.Len() == 0 {
+		return false
+	}
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		return true
+	}
+	return c.maxBytes > 0 && c.size > c.maxBytes
+}
+
+// remove drops el from memory. c.mu must be held.
+func (c *Cache) remove(el *list.Element) {
+	entry := c.lru.Remove(el).(*cacheEntry)
+	delete(c.data, entry.key)
+	c.size -= entry.size()
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.Bytes = c.size
+	return stats
+}
+
+// Keys returns the sorted keys of every entry held in memory or on disk.
+func (c *Cache) Keys() []string {
+	seen := make(map[string]bool)
+	c.mu.Lock()
+	for key := range c.data {
+		seen[key] = true
+	}
+	c.mu.Unlock()
+	if c.disk != nil {
+		for _, key := range c.disk.keys() {
+			seen[key] = true
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Clear removes every entry from memory and disk and resets the stats.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	c.data = make(map[string]*list.Element)
+	c.lru.Init()
+	c.size = 0
+	c.stats = Stats{}
+	c.mu.Unlock()
+	if c.disk != nil {
+		c.disk.clear()
+	}
+}
+
+// Close stops the reap loop. The cache stays usable afterwards but entries
+// are no longer expired from memory in the background. Close is safe to call
+// more than once.
+func (c *Cache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
 }
 
 func (c *Cache) reapLoop(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
 		c.mu.Lock()
-		for key, entry := range c.data {
-			if time.Since(entry.created) > interval {
-				delete(c.data, key)
+		for _, el := range c.data {
+			if time.Since(el.Value.(*cacheEntry).created) > interval {
+				c.remove(el)
+				c.stats.Reaped++
 			}
 		}
 		c.mu.Unlock()
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// interrupter turns Ctrl-C into cancellation of the running command instead
+// of terminating the whole program. Between commands it calls idle instead.
+type interrupter struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	idle   func()
+}
+
+func newInterrupter(idle func()) *interrupter {
+	in := &interrupter{idle: idle}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	go func() {
+		for range sigs {
+			in.interrupt()
+		}
+	}()
+	return in
+}
+
+func (in *interrupter) interrupt() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.cancel != nil {
+		in.cancel()
+		return
+	}
+	if in.idle != nil {
+		in.idle()
+	}
+}
+
+// remindToExit is what Ctrl-C between commands does in the interactive REPL.
+func remindToExit() {
+	fmt.Print("\n(type exit to quit)\nPokedex >")
+}
+
+// quitOnInterrupt is what Ctrl-C between commands does outside the REPL,
+// where nobody is there to type exit: it runs the shutdown hooks and exits
+// with 130, the status of a process stopped by SIGINT.
+func quitOnInterrupt(hooks *shutdownHooks, exit func(status int)) func() {
+	return func() {
+		exit(exitWith(hooks, 130))
+	}
+}
+
+// commandContext returns the context for one command. The returned function
+// must be called once the command has finished.
+func (in *interrupter) commandContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in.mu.Lock()
+	in.cancel = cancel
+	in.mu.Unlock()
+	return ctx, func() {
+		in.mu.Lock()
+		in.cancel = nil
+		in.mu.Unlock()
+		cancel()
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestInterruptBetweenCommandsQuitsOutsideREPL(t *testing.T) {
+	saved := false
+	hooks := &shutdownHooks{}
+	hooks.add("save the profile", func() error {
+		saved = true
+		return nil
+	})
+	status := -1
+	in := &interrupter{idle: quitOnInterrupt(hooks, func(s int) { status = s })}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	in.interrupt()
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	if status != 130 {
+		t.Errorf("expected exit status 130, got %v", status)
+	}
+	if !saved {
+		t.Errorf("expected the shutdown hooks to run")
+	}
+	if len(out) != 0 {
+		t.Errorf("expected nothing on stdout, got %q", out)
+	}
+}
+
+func TestInterruptCancelsRunningCommand(t *testing.T) {
+	idle := false
+	in := &interrupter{idle: func() { idle = true }}
+	ctx, done := in.commandContext()
+	in.interrupt()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("expected the command to be cancelled, got %v", ctx.Err())
+	}
+	if idle {
+		t.Errorf("expected idle not to be called while a command runs")
+	}
+	done()
+
+	in.interrupt()
+	if !idle {
+		t.Errorf("expected idle to be called between commands")
+	}
+}
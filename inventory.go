@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+// Bag maps PokeAPI item names to how many of them the trainer carries.
+type Bag map[string]int
+
+// starterBag is what every new trainer, and every trainer from a save that
+// predates the inventory, starts out with.
+func starterBag() Bag {
+	return Bag{
+		"poke-ball":  20,
+		"great-ball": 5,
+		"ultra-ball": 2,
+		"potion":     3,
+	}
+}
+
+// take removes one item from the bag, reporting false if there was none.
+func (b Bag) take(item string) bool {
+	if b[item] <= 0 {
+		return false
+	}
+	b[item]--
+	if b[item] == 0 {
+		delete(b, item)
+	}
+	return true
+}
+
+func commandBag(ctx context.Context, cfg *Config, args commandArgs) error {
+	cfg.mu.RLock()
+	names := make([]string, 0, len(cfg.Profile.Bag))
+	counts := make(map[string]int, len(cfg.Profile.Bag))
+	for name, count := range cfg.Profile.Bag {
+		names = append(names, name)
+		counts[name] = count
+	}
+	cfg.mu.RUnlock()
+	sort.Strings(names)
+
+	records := make([]record, 0, len(names))
+	for _, name := range names {
+		// Items the PokeAPI does not know are still listed, without details.
+		category, effect := "", ""
+		item, err := cfg.Client.GetItem(ctx, name)
+		var notFound *pokeapi.NotFoundError
+		switch {
+		case err == nil:
+			category, effect = item.Category.Name, item.ShortEffect()
+		case !errors.As(err, &notFound):
+			return err
+		}
+		records = append(records, record{{"name", name}, {"count", counts[name]}, {"category", category}, {"effect", effect}})
+	}
+	return render(cfg, records, func() {
+		fmt.Println("Bag:")
+		if len(records) == 0 {
+			fmt.Println("Your bag is empty!")
+			return
+		}
+		for _, r := range records {
+			name, count, category, effect := r[0].value, r[1].value, r[2].value, r[3].value
+			if category == "" {
+				fmt.Printf("   - %v x%d\n", name, count)
+				continue
+			}
+			fmt.Printf("   - %v x%d (%v): %v\n", name, count, category, effect)
+		}
+	})
+}
@@ -1,46 +1,175 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/UUest/pokecli/internal/lineedit"
+	"github.com/UUest/pokecli/internal/pokeapi"
 	"github.com/UUest/pokecli/internal/pokecache"
 )
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: pokecli [-f file] [-output format] [command [args...]]")
+		fmt.Fprintln(flag.CommandLine.Output(), "Without arguments, commands are read from standard input.")
+		flag.PrintDefaults()
+	}
+	script := flag.String("f", "", "run the commands in `file`, one per line")
+	output := flag.String("output", formatTable, "output `format`: "+strings.Join(outputFormats, ", "))
+	flag.Parse()
+	if !validOutputFormat(*output) {
+		fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", *output)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cacheOpts := []pokecache.Option{pokecache.WithMaxBytes(32 << 20)}
+	if dir, err := os.UserCacheDir(); err == nil {
+		cacheOpts = append(cacheOpts, pokecache.WithDiskStore(filepath.Join(dir, "pokecli"), 7*24*time.Hour))
+	}
+	cache := pokecache.NewCache(5*time.Second, cacheOpts...)
 	cfg := &Config{
-		Cache:   pokecache.NewCache(5 * time.Second),
-		Pokedex: make(map[string]Pokemon),
+		Cache:   cache,
+		Client:  pokeapi.NewClient(pokeapi.WithCache(cache)),
+		DataDir: defaultDataDir(),
+		Rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		Output:  *output,
+	}
+	if err := loadOnStartup(cfg); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+	hooks := newShutdownHooks(cfg)
+	os.Exit(exitWith(hooks, run(cfg, hooks, *script)))
+}
+
+// run reads commands from the command line, a script file or standard
+// input, and returns the exit status.
+func run(cfg *Config, hooks *shutdownHooks, script string) int {
+	quit := quitOnInterrupt(hooks, os.Exit)
+	if flag.NArg() > 0 {
+		status, _ := runWords(cfg, newInterrupter(quit), flag.Args(), false)
+		return status
+	}
+	if script != "" {
+		f, err := os.Open(script)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		return runLines(cfg, newInterrupter(quit), lineedit.New(f, os.Stdout))
+	}
+
+	editor := lineedit.New(os.Stdin, os.Stdout)
+	if !editor.Interactive() {
+		return runLines(cfg, newInterrupter(quit), editor)
+	}
+	editor.SetCompleter(func(line string) []string {
+		return completeLine(cfg, line)
+	})
+	if err := os.MkdirAll(cfg.DataDir, 0o755); err == nil {
+		if err := editor.LoadHistory(filepath.Join(cfg.DataDir, "history")); err != nil {
+			fmt.Printf("Warning: Failed to load history: %v\n", err)
+		}
+		hooks.add("write the history", editor.SaveHistory)
+	}
+	return runLines(cfg, newInterrupter(remindToExit), editor)
+}
+
+// exitWith runs the shutdown hooks and returns the exit status, which a
+// failing hook turns into a failure.
+func exitWith(hooks *shutdownHooks, status int) int {
+	if err := hooks.run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		if status == 0 {
+			status = 1
+		}
+	}
+	return status
+}
+
+// runLines runs commands read from editor until the input ends. At a
+// terminal it behaves as the interactive REPL and keeps going after errors.
+// Otherwise no prompt is shown and the first failing command stops the run.
+// The result is the process exit status.
+func runLines(cfg *Config, interrupts *interrupter, editor *lineedit.Editor) int {
+	interactive := editor.Interactive()
+	prompt := ""
+	if interactive {
+		prompt = "Pokedex >"
 	}
-	scanner := bufio.NewScanner(os.Stdin)
 	for {
-		fmt.Print("Pokedex >")
-		scanner.Scan()
-		text := scanner.Text()
-		cleanText := CleanInput(text)
-		if len(cleanText) != 0 {
-			if command, ok := commands[cleanText[0]]; ok {
-				var err error
-				if cleanText[0] == "explore" && len(cleanText) > 1 {
-					err = command.callback(cfg, cleanText[1])
-				} else if cleanText[0] == "catch" && len(cleanText) > 1 {
-					err = command.callback(cfg, cleanText[1])
-				} else if cleanText[0] == "inspect" && len(cleanText) > 1 {
-					err = command.callback(cfg, cleanText[1])
-				} else {
-					err = command.callback(cfg)
-				}
-				if err != nil {
-					fmt.Printf("Error: %v\n", err)
-				}
-			} else {
-				fmt.Printf("Unknown command: %s\n", cleanText[0])
+		text, err := editor.ReadLine(prompt)
+		if errors.Is(err, lineedit.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			if interactive {
+				commandExit(context.Background(), cfg, newArgs())
 			}
-		} else {
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		status, exit := runLine(cfg, interrupts, text, interactive)
+		if exit || (status != 0 && !interactive) {
+			return status
+		}
+	}
+}
+
+// runLine runs a single command line and returns its exit status, and
+// whether the command asked to exit. Blank lines and, outside the REPL,
+// # comments are skipped.
+func runLine(cfg *Config, interrupts *interrupter, text string, interactive bool) (status int, exit bool) {
+	cleanText := CleanInput(text)
+	if len(cleanText) == 0 || (!interactive && strings.HasPrefix(cleanText[0], "#")) {
+		if interactive {
 			fmt.Println("No command entered")
-			continue
 		}
+		return 0, false
+	}
+	return runWords(cfg, interrupts, cleanText, interactive)
+}
+
+// runWords runs the command named by the first word with the rest as its
+// arguments, which are passed on as they are. The command line's arguments
+// are run this way so that an argument the shell quoted keeps its spaces.
+func runWords(cfg *Config, interrupts *interrupter, words []string, interactive bool) (status int, exit bool) {
+	errOut := os.Stderr
+	if interactive {
+		errOut = os.Stdout
+	}
+	name := strings.ToLower(words[0])
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(errOut, "Unknown command: %s\n", name)
+		return 1, false
+	}
+	ctx, done := interrupts.commandContext()
+	err := runCommand(ctx, cfg, command, words[1:])
+	done()
+	if errors.Is(err, errExit) {
+		return 0, true
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(errOut, "Cancelled")
+		return 130, false
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "Error: %v\n", err)
+		return 1, false
 	}
+	return 0, false
 }
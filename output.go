@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Output formats. The table format is the usual human-readable output; the
+// others print the same data as structured records for scripts.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatCSV   = "csv"
+)
+
+var outputFormats = []string{formatTable, formatJSON, formatYAML, formatCSV}
+
+func validOutputFormat(format string) bool {
+	for _, f := range outputFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// field is one named value of a record. Values are strings, ints, float64s,
+// bools, []string or nested records.
+type field struct {
+	name  string
+	value any
+}
+
+// record is a structured result row. Fields keep their order so that every
+// format prints them the same way.
+type record []field
+
+// render prints records in the configured output format, or calls text to
+// print the human-readable table output.
+func render(cfg *Config, records []record, text func()) error {
+	if cfg.Output == "" || cfg.Output == formatTable {
+		text()
+		return nil
+	}
+	return writeRecords(os.Stdout, cfg.Output, records)
+}
+
+func writeRecords(w io.Writer, format string, records []record) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, records)
+	case formatYAML:
+		return writeYAML(w, records)
+	case formatCSV:
+		return writeCSV(w, records)
+	}
+	return fmt.Errorf("Unknown output format: %s", format)
+}
+
+func writeJSON(w io.Writer, records []record) error {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, r := range records {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := appendJSON(&buf, r); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err := out.WriteTo(w)
+	return err
+}
+
+// appendJSON writes value as JSON. Records are written by hand because
+// encoding/json would sort map keys and lose the field order.
+func appendJSON(buf *bytes.Buffer, value any) error {
+	r, ok := value.(record)
+	if !ok {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(raw)
+		return nil
+	}
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		if err := appendJSON(buf, f.value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func writeYAML(w io.Writer, records []record) error {
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+	var buf bytes.Buffer
+	for _, r := range records {
+		writeYAMLRecord(&buf, r, "- ", "  ")
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// writeYAMLRecord writes r as a block mapping. The first line starts with
+// first and the others with indent, which lets a record open a list item.
+func writeYAMLRecord(buf *bytes.Buffer, r record, first, indent string) {
+	if len(r) == 0 {
+		fmt.Fprintf(buf, "%s{}\n", first)
+		return
+	}
+	prefix := first
+	for _, f := range r {
+		switch v := f.value.(type) {
+		case record:
+			if len(v) == 0 {
+				fmt.Fprintf(buf, "%s%s: {}\n", prefix, f.name)
+				break
+			}
+			fmt.Fprintf(buf, "%s%s:\n", prefix, f.name)
+			writeYAMLRecord(buf, v, indent+"  ", indent+"  ")
+		case []string:
+			if len(v) == 0 {
+				fmt.Fprintf(buf, "%s%s: []\n", prefix, f.name)
+				break
+			}
+			fmt.Fprintf(buf, "%s%s:\n", prefix, f.name)
+			for _, s := range v {
+				fmt.Fprintf(buf, "%s  - %s\n", indent, yamlScalar(s))
+			}
+		default:
+			fmt.Fprintf(buf, "%s%s: %s\n", prefix, f.name, yamlScalar(v))
+		}
+		prefix = indent
+	}
+}
+
+var plainYAML = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9 ._/()-]*$`)
+
+// yamlScalar formats a scalar, quoting strings that YAML would otherwise
+// read as another type or that contain special characters.
+func yamlScalar(value any) string {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+		return strconv.Quote(s)
+	}
+	if !plainYAML.MatchString(s) || strings.HasSuffix(s, " ") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// writeCSV writes a header taken from the first record and one row per
+// record. Nested records become dotted columns such as stats.hp, and lists
+// are joined with semicolons.
+func writeCSV(w io.Writer, records []record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	var header []string
+	for _, f := range flatten(records[0], "") {
+		header = append(header, f.name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range records {
+		var row []string
+		for _, f := range flatten(r, "") {
+			switch v := f.value.(type) {
+			case []string:
+				row = append(row, strings.Join(v, ";"))
+			default:
+				row = append(row, fmt.Sprint(v))
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func flatten(r record, prefix string) record {
+	var flat record
+	for _, f := range r {
+		if nested, ok := f.value.(record); ok {
+			flat = append(flat, flatten(nested, prefix+f.name+".")...)
+			continue
+		}
+		flat = append(flat, field{name: prefix + f.name, value: f.value})
+	}
+	return flat
+}
+
+func commandSet(ctx context.Context, cfg *Config, args commandArgs) error {
+	setting, value := args.arg(0), args.arg(1)
+	switch setting {
+	case "output":
+		if !validOutputFormat(value) {
+			return fmt.Errorf("Unknown output format: %s (choose from %s)", value, strings.Join(outputFormats, ", "))
+		}
+		cfg.Output = value
+		fmt.Printf("Output format set to %s\n", value)
+	default:
+		return fmt.Errorf("Unknown setting: %s", setting)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/UUest/pokecli/internal/pokecache"
+)
+
+func TestWriteRecords(t *testing.T) {
+	records := []record{
+		{
+			{"name", "mr-mime"},
+			{"height", 13},
+			{"stats", record{{"hp", 40}, {"special-attack", 100}}},
+			{"types", []string{"psychic", "fairy"}},
+		},
+		{
+			{"name", "yes"},
+			{"height", 4},
+			{"stats", record{{"hp", 1}, {"special-attack", 2}}},
+			{"types", []string{}},
+		},
+	}
+
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: formatJSON,
+			expected: `[
+  {
+    "name": "mr-mime",
+    "height": 13,
+    "stats": {
+      "hp": 40,
+      "special-attack": 100
+    },
+    "types": [
+      "psychic",
+      "fairy"
+    ]
+  },
+  {
+    "name": "yes",
+    "height": 4,
+    "stats": {
+      "hp": 1,
+      "special-attack": 2
+    },
+    "types": []
+  }
+]
+`,
+		},
+		{
+			format: formatYAML,
+			expected: `- name: mr-mime
+  height: 13
+  stats:
+    hp: 40
+    special-attack: 100
+  types:
+    - psychic
+    - fairy
+- name: "yes"
+  height: 4
+  stats:
+    hp: 1
+    special-attack: 2
+  types: []
+`,
+		},
+		{
+			format: formatCSV,
+			expected: "name,height,stats.hp,stats.special-attack,types\n" +
+				"mr-mime,13,40,100,psychic;fairy\n" +
+				"yes,4,1,2,\n",
+		},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := writeRecords(&buf, c.format, records); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.format, err)
+		}
+		if buf.String() != c.expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", c.format, c.expected, buf.String())
+		}
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	cases := []struct {
+		input    any
+		expected string
+	}{
+		{input: "pidgey", expected: "pidgey"},
+		{input: "no", expected: `"no"`},
+		{input: "", expected: `""`},
+		{input: "123", expected: `"123"`},
+		{input: "https://pokeapi.co/api/v2/", expected: `"https://pokeapi.co/api/v2/"`},
+		{input: 42, expected: "42"},
+	}
+
+	for _, c := range cases {
+		if actual := yamlScalar(c.input); actual != c.expected {
+			t.Errorf("%v: expected %v, got %v", c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	cfg := &Config{Profile: newProfile("ash")}
+	if err := runCommand(context.Background(), cfg, commands["set"], []string{"output", "json"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Output != formatJSON {
+		t.Errorf("expected output format json, got %v", cfg.Output)
+	}
+	if err := runCommand(context.Background(), cfg, commands["set"], []string{"output", "xml"}); err == nil {
+		t.Errorf("expected an error for an unknown output format")
+	}
+	if err := runCommand(context.Background(), cfg, commands["set"], []string{"colour", "red"}); err == nil {
+		t.Errorf("expected an error for an unknown setting")
+	}
+}
+
+// captureJSON runs a command with JSON output and decodes what it printed.
+func captureJSON(t *testing.T, cfg *Config, name string, words ...string) []map[string]any {
+	t.Helper()
+	cfg.Output = formatJSON
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = runCommand(context.Background(), cfg, commands[name], words)
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%v: unexpected error: %v", name, err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal(out, &records); err != nil {
+		t.Fatalf("%v: expected JSON, got %q: %v", name, out, err)
+	}
+	return records
+}
+
+func TestStructuredOutput(t *testing.T) {
+	cfg := newTestConfig(t, http.NotFound)
+	cfg.DataDir = t.TempDir()
+	cfg.Cache = pokecache.NewCache(time.Minute)
+	cfg.Profile.Bag = Bag{"poke-ball": 5}
+
+	bag := captureJSON(t, cfg, "bag")
+	if len(bag) != 1 || bag[0]["name"] != "poke-ball" || bag[0]["count"] != 5.0 {
+		t.Errorf("expected five poke-balls, got %v", bag)
+	}
+	stats := captureJSON(t, cfg, "cache", "stats")
+	if len(stats) != 1 || stats[0]["entries"] != 0.0 {
+		t.Errorf("expected an empty cache, got %v", stats)
+	}
+	if keys := captureJSON(t, cfg, "cache", "keys"); len(keys) != 0 {
+		t.Errorf("expected no keys, got %v", keys)
+	}
+	profile := captureJSON(t, cfg, "profile")
+	if len(profile) != 1 || profile[0]["name"] != "ash" || profile[0]["current"] != true {
+		t.Errorf("expected the current profile, got %v", profile)
+	}
+	profiles := captureJSON(t, cfg, "profile", "list")
+	if len(profiles) != 1 || profiles[0]["name"] != "ash" {
+		t.Errorf("expected ash's saved profile, got %v", profiles)
+	}
+}
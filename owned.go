@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+const (
+	maxIV     = 31
+	shinyOdds = 4096
+	// defaultLevel is the level of Pokemon caught without having been met
+	// at the last explored location, and of Pokemon from older saves.
+	defaultLevel = 5
+)
+
+// statNames are the PokeAPI names of the six stats, in the games' order.
+var statNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+// OwnedPokemon is one caught Pokemon. The Pokedex records each kind of
+// Pokemon once; a trainer may own any number of the same kind, each with its
+// own level, IVs, nature and so on.
+type OwnedPokemon struct {
+	ID int `json:"id"`
+	// Pokemon is the PokeAPI name, which is also its key in the Pokedex.
+	Pokemon  string         `json:"pokemon"`
+	Nickname string         `json:"nickname,omitempty"`
+	Level    int            `json:"level"`
+	IVs      map[string]int `json:"ivs"`
+	EVs      map[string]int `json:"evs,omitempty"`
+	Nature   string         `json:"nature,omitempty"`
+	Gender   string         `json:"gender,omitempty"`
+	Shiny    bool           `json:"shiny,omitempty"`
+	CaughtAt time.Time      `json:"caught_at"`
+}
+
+// label names o for messages, such as "pidgey #3 Lv. 4".
+func (o *OwnedPokemon) label() string {
+	name := o.Pokemon
+	if o.Nickname != "" {
+		name = fmt.Sprintf("%s (%s)", o.Nickname, o.Pokemon)
+	}
+	if o.Shiny {
+		name += " (shiny)"
+	}
+	return fmt.Sprintf("%s #%d Lv. %d", name, o.ID, o.Level)
+}
+
+// levelRange is the range of levels a Pokemon is met at.
+type levelRange struct {
+	min, max int
+}
+
+func (r levelRange) roll(rng *rand.Rand) int {
+	if r.max <= r.min {
+		return r.min
+	}
+	return r.min + rng.Intn(r.max-r.min+1)
+}
+
+// encounterLevels collects the levels each Pokemon is met at in area, over
+// every game version and encounter method.
+func encounterLevels(area pokeapi.LocationArea) map[string]levelRange {
+	levels := make(map[string]levelRange, len(area.PokemonEncounters))
+	for _, encounter := range area.PokemonEncounters {
+		var r levelRange
+		for _, version := range encounter.VersionDetails {
+			for _, detail := range version.EncounterDetails {
+				if r.min == 0 || detail.MinLevel < r.min {
+					r.min = detail.MinLevel
+				}
+				if detail.MaxLevel > r.max {
+					r.max = detail.MaxLevel
+				}
+			}
+		}
+		if r.min > 0 {
+			levels[encounter.Pokemon.Name] = r
+		}
+	}
+	return levels
+}
+
+func rollIVs(rng *rand.Rand) map[string]int {
+	ivs := make(map[string]int, len(statNames))
+	for _, stat := range statNames {
+		ivs[stat] = rng.Intn(maxIV + 1)
+	}
+	return ivs
+}
+
+// rollGender picks a gender from a species' gender rate, the chance of being
+// female in eighths, or -1 for genderless species.
+func rollGender(rng *rand.Rand, genderRate int) string {
+	switch {
+	case genderRate < 0:
+		return "genderless"
+	case rng.Intn(8) < genderRate:
+		return "female"
+	default:
+		return "male"
+	}
+}
+
+// rollOwned creates a newly caught Pokemon. It gets its ID when it is added
+// to a profile.
+func rollOwned(rng *rand.Rand, name string, levels levelRange, natures []string, genderRate int) *OwnedPokemon {
+	o := &OwnedPokemon{
+		Pokemon:  name,
+		Level:    levels.roll(rng),
+		IVs:      rollIVs(rng),
+		Gender:   rollGender(rng, genderRate),
+		Shiny:    rng.Intn(shinyOdds) == 0,
+		CaughtAt: time.Now(),
+	}
+	if len(natures) > 0 {
+		o.Nature = natures[rng.Intn(len(natures))]
+	}
+	return o
+}
+
+// add gives o the next ID and puts it in the party, or the PC once the
+// party is full. It returns the box number, or 0 for the party.
+func (p *Profile) add(o *OwnedPokemon) int {
+	p.NextID++
+	o.ID = p.NextID
+	p.Owned[o.ID] = o
+	return p.store(o.ID)
+}
+
+// ownedByID returns every owned Pokemon in the order they were caught.
+func (p *Profile) ownedByID() []*OwnedPokemon {
+	owned := make([]*OwnedPokemon, 0, len(p.Owned))
+	for _, o := range p.Owned {
+		owned = append(owned, o)
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[i].ID < owned[j].ID
+	})
+	return owned
+}
+
+// findOwned looks up an owned Pokemon by ID ("#3"), by nickname, or by its
+// Pokemon name when only one of that kind is owned.
+func (p *Profile) findOwned(ref string) (*OwnedPokemon, error) {
+	if strings.HasPrefix(ref, "#") {
+		id, err := strconv.Atoi(ref[1:])
+		if o, ok := p.Owned[id]; err == nil && ok {
+			return o, nil
+		}
+		return nil, fmt.Errorf("You have no Pokemon with ID %s", ref)
+	}
+	var matches []*OwnedPokemon
+	for _, o := range p.ownedByID() {
+		if o.Nickname == ref {
+			return o, nil
+		}
+		if o.Pokemon == ref {
+			matches = append(matches, o)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("Pokemon not found in Pokedex")
+	case 1:
+		return matches[0], nil
+	}
+	ids := make([]string, 0, len(matches))
+	for _, o := range matches {
+		ids = append(ids, fmt.Sprintf("#%d", o.ID))
+	}
+	return nil, fmt.Errorf("You own %d %s, pick one by ID: %s", len(matches), ref, strings.Join(ids, ", "))
+}
+
+// ref returns the shortest way to name o that findOwned understands.
+func (p *Profile) ref(o *OwnedPokemon) string {
+	if o.Nickname != "" {
+		return o.Nickname
+	}
+	for _, other := range p.Owned {
+		if other != o && other.Pokemon == o.Pokemon {
+			return fmt.Sprintf("#%d", o.ID)
+		}
+	}
+	return o.Pokemon
+}
+
+// adoptLegacy turns the caught Pokemon of a save from before owned Pokemon
+// existed into one owned Pokemon each, keeping their party and box places.
+// Levels, natures and genders were never recorded, so they get the default
+// level and no nature or gender.
+func (p *Profile) adoptLegacy(party []string, boxes [][]string) {
+	ids := make(map[string]int, len(p.Pokedex))
+	names := make([]string, 0, len(p.Pokedex))
+	for name := range p.Pokedex {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, name := range names {
+		p.NextID++
+		p.Owned[p.NextID] = &OwnedPokemon{
+			ID:      p.NextID,
+			Pokemon: name,
+			Level:   defaultLevel,
+			IVs:     rollIVs(rng),
+		}
+		ids[name] = p.NextID
+	}
+	p.Party = p.Party[:0]
+	for _, name := range party {
+		if id, ok := ids[name]; ok {
+			p.Party = append(p.Party, id)
+		}
+	}
+	p.Boxes = make([][]int, len(boxes))
+	for i, box := range boxes {
+		for _, name := range box {
+			if id, ok := ids[name]; ok {
+				p.Boxes[i] = append(p.Boxes[i], id)
+			}
+		}
+	}
+}
+
+var nicknameRe = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+func commandNickname(ctx context.Context, cfg *Config, args commandArgs) error {
+	ref, nickname := args.arg(0), args.arg(1)
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	o, err := cfg.Profile.findOwned(ref)
+	if err != nil {
+		return err
+	}
+	if nickname != "" {
+		if !nicknameRe.MatchString(nickname) {
+			return fmt.Errorf("Nicknames must start with a letter and may only contain letters, digits, '-' and '_'")
+		}
+		if _, ok := cfg.Profile.Pokedex[nickname]; ok {
+			return fmt.Errorf("%s is already the name of a Pokemon", nickname)
+		}
+		for _, other := range cfg.Profile.Owned {
+			if other != o && other.Nickname == nickname {
+				return fmt.Errorf("%s is already the nickname of %s", nickname, other.label())
+			}
+		}
+	}
+	o.Nickname = nickname
+	if nickname == "" {
+		fmt.Printf("Cleared the nickname of %v\n", o.label())
+	} else {
+		fmt.Printf("%v is now called %v\n", o.Pokemon, nickname)
+	}
+	autosave(cfg)
+	return nil
+}
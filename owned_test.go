@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+func TestFindOwned(t *testing.T) {
+	profile := profileWith(2)
+	profile.add(&OwnedPokemon{Pokemon: "pokemon-01", Nickname: "sparky"})
+
+	cases := []struct {
+		ref string
+		id  int
+		err string
+	}{
+		{ref: "#2", id: 2},
+		{ref: "sparky", id: 3},
+		{ref: "pokemon-02", id: 2},
+		{ref: "pokemon-01", err: "pick one by ID: #1, #3"},
+		{ref: "#9", err: "no Pokemon with ID #9"},
+		{ref: "pikachu", err: "not found"},
+	}
+
+	for _, c := range cases {
+		o, err := profile.findOwned(c.ref)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%v: expected an error containing %q, got %v", c.ref, c.err, err)
+			}
+			continue
+		}
+		if err != nil || o.ID != c.id {
+			t.Errorf("%v: expected ID %v, got %v, %v", c.ref, c.id, o, err)
+		}
+	}
+
+	if ref := profile.ref(profile.Owned[1]); ref != "#1" {
+		t.Errorf("expected an ambiguous Pokemon to be referred to by ID, got %v", ref)
+	}
+	if ref := profile.ref(profile.Owned[2]); ref != "pokemon-02" {
+		t.Errorf("expected a unique Pokemon to be referred to by name, got %v", ref)
+	}
+}
+
+func TestEncounterLevels(t *testing.T) {
+	area := pokeapi.LocationArea{}
+	raw := `{"pokemon_encounters": [
+		{"pokemon": {"name": "pidgey"}, "version_details": [
+			{"encounter_details": [{"min_level": 3, "max_level": 4}, {"min_level": 2, "max_level": 2}]},
+			{"encounter_details": [{"min_level": 5, "max_level": 7}]}
+		]},
+		{"pokemon": {"name": "missingno"}, "version_details": []}
+	]}`
+	if err := json.Unmarshal([]byte(raw), &area); err != nil {
+		t.Fatal(err)
+	}
+	levels := encounterLevels(area)
+	if levels["pidgey"] != (levelRange{2, 7}) {
+		t.Errorf("expected pidgey at levels 2-7, got %v", levels["pidgey"])
+	}
+	if _, ok := levels["missingno"]; ok {
+		t.Errorf("expected no levels for a Pokemon without encounter details")
+	}
+}
+
+func TestRollOwned(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		o := rollOwned(rng, "magnemite", levelRange{10, 12}, []string{"hardy", "bold"}, -1)
+		if o.Level < 10 || o.Level > 12 {
+			t.Fatalf("expected a level from 10 to 12, got %v", o.Level)
+		}
+		if o.Gender != "genderless" {
+			t.Fatalf("expected magnemite to be genderless, got %v", o.Gender)
+		}
+		if o.Nature != "hardy" && o.Nature != "bold" {
+			t.Fatalf("expected one of the given natures, got %v", o.Nature)
+		}
+		for _, stat := range statNames {
+			if iv, ok := o.IVs[stat]; !ok || iv < 0 || iv > maxIV {
+				t.Fatalf("expected an IV from 0 to %v for %v, got %v", maxIV, stat, o.IVs)
+			}
+		}
+	}
+	if gender := rollGender(rng, 8); gender != "female" {
+		t.Errorf("expected a female-only species to roll female, got %v", gender)
+	}
+	if gender := rollGender(rng, 0); gender != "male" {
+		t.Errorf("expected a male-only species to roll male, got %v", gender)
+	}
+}
+
+func TestCatchSameKindTwice(t *testing.T) {
+	cfg := newTestConfig(t, battleHandler)
+	cfg.Encounters = map[string]levelRange{"pidgey": {3, 3}}
+	catch := func() error {
+		return runCommand(context.Background(), cfg, commands["catch"], []string{"pidgey", "--ball", "master-ball"})
+	}
+	if err := catch(); err == nil {
+		t.Fatalf("expected an error without a master ball")
+	}
+	cfg.Profile.Bag["master-ball"] = 2
+	// The first catch is a throw, since there is nothing to battle with yet.
+	if err := catch(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// With a Pokemon in the party, catching starts a battle instead.
+	if err := catch(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Battle == nil || cfg.Profile.Bag["master-ball"] != 1 {
+		t.Fatalf("expected a battle without throwing a ball, %v master balls left", cfg.Profile.Bag["master-ball"])
+	}
+	if err := catch(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Profile.Owned) != 2 || len(cfg.Profile.Pokedex) != 1 {
+		t.Fatalf("expected two pidgey of one kind, got %v owned and %v kinds", len(cfg.Profile.Owned), len(cfg.Profile.Pokedex))
+	}
+	for _, o := range cfg.Profile.Owned {
+		if o.Level != 3 {
+			t.Errorf("expected the encounter level 3, got %v", o.Level)
+		}
+		if o.Nature == "" || o.Gender == "" {
+			t.Errorf("expected a nature and gender, got %q and %q", o.Nature, o.Gender)
+		}
+	}
+}
+
+func TestNickname(t *testing.T) {
+	cfg := &Config{Profile: profileWith(2)}
+	if err := runCommand(context.Background(), cfg, commands["nickname"], []string{"pokemon-01", "sparky"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o, err := cfg.Profile.findOwned("sparky"); err != nil || o.ID != 1 {
+		t.Errorf("expected sparky to be #1, got %v, %v", o, err)
+	}
+	if err := runCommand(context.Background(), cfg, commands["nickname"], []string{"pokemon-02", "sparky"}); err == nil {
+		t.Errorf("expected an error for a nickname already in use")
+	}
+	if err := runCommand(context.Background(), cfg, commands["nickname"], []string{"pokemon-02", "pokemon-01"}); err == nil {
+		t.Errorf("expected an error for a nickname that is a Pokemon name")
+	}
+	if err := runCommand(context.Background(), cfg, commands["nickname"], []string{"sparky"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Profile.Owned[1].Nickname != "" {
+		t.Errorf("expected the nickname to be cleared")
+	}
+}
+
+func TestReadSaveVersionFour(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	raw := `{"version": 4, "pokedex": {"pidgey": {"name": "pidgey"}, "rattata": {"name": "rattata"}, "zubat": {"name": "zubat"}},
+		"party": ["zubat", "pidgey"], "boxes": [["rattata"]]}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := readSave(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Owned) != 3 {
+		t.Fatalf("expected one owned Pokemon per caught Pokemon, got %v", loaded.Owned)
+	}
+	var party []string
+	for _, id := range loaded.Party {
+		party = append(party, loaded.Owned[id].Pokemon)
+	}
+	if strings.Join(party, ",") != "zubat,pidgey" {
+		t.Errorf("expected the party order to be kept, got %v", party)
+	}
+	if len(loaded.Boxes) != 1 || loaded.Owned[loaded.Boxes[0][0]].Pokemon != "rattata" {
+		t.Errorf("expected rattata to stay in Box 1, got %v", loaded.Boxes)
+	}
+	if loaded.Owned[loaded.Party[0]].Level != defaultLevel {
+		t.Errorf("expected migrated Pokemon at the default level")
+	}
+}
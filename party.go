@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+const (
+	partySize = 6
+	boxSize   = 30
+)
+
+// arrange makes sure every owned Pokemon is in exactly one place, either the
+// party or a PC box. Pokemon that are in neither fill up the party first and
+// then the boxes, in the order they were caught. Entries for Pokemon that
+// are no longer owned, or that appear twice, are dropped.
+func (p *Profile) arrange() {
+	placed := make(map[int]bool)
+	keep := func(ids []int) []int {
+		kept := ids[:0]
+		for _, id := range ids {
+			if _, ok := p.Owned[id]; ok && !placed[id] {
+				placed[id] = true
+				kept = append(kept, id)
+			}
+		}
+		return kept
+	}
+	p.Party = keep(p.Party)
+	if len(p.Party) > partySize {
+		overflow := p.Party[partySize:]
+		p.Party = p.Party[:partySize:partySize]
+		for _, id := range overflow {
+			delete(placed, id)
+		}
+	}
+	for i := range p.Boxes {
+		p.Boxes[i] = keep(p.Boxes[i])
+	}
+
+	for _, o := range p.ownedByID() {
+		if !placed[o.ID] {
+			p.store(o.ID)
+		}
+	}
+}
+
+// store puts an owned Pokemon in the party, or in the PC once the party is
+// full. It returns the box number, or 0 for the party.
+func (p *Profile) store(id int) int {
+	if len(p.Party) < partySize {
+		p.Party = append(p.Party, id)
+		return 0
+	}
+	box := p.freeBox()
+	p.Boxes[box] = append(p.Boxes[box], id)
+	return box + 1
+}
+
+// freeBox returns the index of the first box with room, opening a new box
+// when all are full.
+func (p *Profile) freeBox() int {
+	for i, box := range p.Boxes {
+		if len(box) < boxSize {
+			return i
+		}
+	}
+	p.Boxes = append(p.Boxes, nil)
+	return len(p.Boxes) - 1
+}
+
+func (p *Profile) partySlot(id int) int {
+	for i, member := range p.Party {
+		if member == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// boxSlot returns the box and position of id in the PC, or -1, -1.
+func (p *Profile) boxSlot(id int) (box, slot int) {
+	for i, b := range p.Boxes {
+		for j, member := range b {
+			if member == id {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// withdraw moves a Pokemon from the PC to the end of the party.
+func (p *Profile) withdraw(ref string) (*OwnedPokemon, error) {
+	o, err := p.findOwned(ref)
+	if err != nil {
+		return nil, err
+	}
+	if p.partySlot(o.ID) >= 0 {
+		return nil, fmt.Errorf("%s is already in your party", o.label())
+	}
+	if len(p.Party) >= partySize {
+		return nil, fmt.Errorf("Your party is full, deposit a Pokemon first")
+	}
+	box, slot := p.boxSlot(o.ID)
+	p.Boxes[box] = append(p.Boxes[box][:slot], p.Boxes[box][slot+1:]...)
+	p.Party = append(p.Party, o.ID)
+	return o, nil
+}
+
+// deposit moves a Pokemon from the party into the PC, in the given box
+// (counted from 1) or in the first box with room when box is 0. The last
+// Pokemon in the party cannot be deposited.
+func (p *Profile) deposit(ref string, box int) (*OwnedPokemon, int, error) {
+	o, err := p.findOwned(ref)
+	if err != nil {
+		return nil, 0, err
+	}
+	slot := p.partySlot(o.ID)
+	if slot < 0 {
+		return nil, 0, fmt.Errorf("%s is not in your party", o.label())
+	}
+	if len(p.Party) == 1 {
+		return nil, 0, fmt.Errorf("%s is the last Pokemon in your party", o.label())
+	}
+	if box == 0 {
+		box = p.freeBox() + 1
+	}
+	if box < 1 || box > len(p.Boxes) {
+		return nil, 0, fmt.Errorf("There is no Box %d", box)
+	}
+	if len(p.Boxes[box-1]) >= boxSize {
+		return nil, 0, fmt.Errorf("Box %d is full", box)
+	}
+	p.Party = append(p.Party[:slot], p.Party[slot+1:]...)
+	p.Boxes[box-1] = append(p.Boxes[box-1], o.ID)
+	return o, box, nil
+}
+
+// swap exchanges two party members, given by slot number or as findOwned
+// understands them.
+func (p *Profile) swap(a, b string) error {
+	i, err := p.findPartySlot(a)
+	if err != nil {
+		return err
+	}
+	j, err := p.findPartySlot(b)
+	if err != nil {
+		return err
+	}
+	p.Party[i], p.Party[j] = p.Party[j], p.Party[i]
+	return nil
+}
+
+func (p *Profile) findPartySlot(nameOrSlot string) (int, error) {
+	if n, err := strconv.Atoi(nameOrSlot); err == nil {
+		if n < 1 || n > len(p.Party) {
+			return 0, fmt.Errorf("There is no Pokemon in party slot %d", n)
+		}
+		return n - 1, nil
+	}
+	o, err := p.findOwned(nameOrSlot)
+	if err != nil {
+		return 0, err
+	}
+	slot := p.partySlot(o.ID)
+	if slot < 0 {
+		return 0, fmt.Errorf("%s is not in your party", o.label())
+	}
+	return slot, nil
+}
+
+func commandParty(ctx context.Context, cfg *Config, args commandArgs) error {
+	action := args.arg(0)
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	profile := cfg.Profile
+	switch action {
+	case "":
+		return printParty(cfg)
+	case "add":
+		if args.arg(1) == "" {
+			return fmt.Errorf("Usage: party add <pokemon>")
+		}
+		o, err := profile.withdraw(args.arg(1))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%v joined your party!\n", o.label())
+	case "remove":
+		if args.arg(1) == "" {
+			return fmt.Errorf("Usage: party remove <pokemon>")
+		}
+		if err := checkNotBattling(cfg, args.arg(1)); err != nil {
+			return err
+		}
+		o, box, err := profile.deposit(args.arg(1), 0)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%v was sent to Box %d\n", o.label(), box)
+	case "swap":
+		if args.arg(1) == "" || args.arg(2) == "" {
+			return fmt.Errorf("Usage: party swap <pokemon|slot> <pokemon|slot>")
+		}
+		if err := profile.swap(args.arg(1), args.arg(2)); err != nil {
+			return err
+		}
+		autosave(cfg)
+		return printParty(cfg)
+	default:
+		return fmt.Errorf("Unknown party action: %s", action)
+	}
+	autosave(cfg)
+	return nil
+}
+
+// printParty shows the party in order. cfg.mu must be held.
+func printParty(cfg *Config) error {
+	party := cfg.Profile.Party
+	records := make([]record, 0, len(party))
+	for i, id := range party {
+		records = append(records, append(record{{"slot", i + 1}}, slotRecord(cfg.Profile.Owned[id])...))
+	}
+	return render(cfg, records, func() {
+		fmt.Println("Party:")
+		if len(party) == 0 {
+			fmt.Println("Your party is empty!")
+			return
+		}
+		for i, id := range party {
+			fmt.Printf("   %d. %v\n", i+1, cfg.Profile.Owned[id].label())
+		}
+	})
+}
+
+// slotRecord describes an owned Pokemon in party and box listings.
+func slotRecord(o *OwnedPokemon) record {
+	return record{
+		{"id", o.ID},
+		{"name", o.Pokemon},
+		{"nickname", o.Nickname},
+		{"level", o.Level},
+	}
+}
+
+func commandBox(ctx context.Context, cfg *Config, args commandArgs) error {
+	if action := args.arg(0); action != "" && action != "list" {
+		return fmt.Errorf("Unknown box action: %s", action)
+	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	boxes := cfg.Profile.Boxes
+	var records []record
+	for i, box := range boxes {
+		for j, id := range box {
+			records = append(records, append(record{{"box", i + 1}, {"slot", j + 1}}, slotRecord(cfg.Profile.Owned[id])...))
+		}
+	}
+	return render(cfg, records, func() {
+		if len(records) == 0 {
+			fmt.Println("Your PC is empty!")
+			return
+		}
+		for i, box := range boxes {
+			fmt.Printf("Box %d (%d/%d):\n", i+1, len(box), boxSize)
+			for _, id := range box {
+				fmt.Printf("   - %v\n", cfg.Profile.Owned[id].label())
+			}
+		}
+	})
+}
+
+func commandWithdraw(ctx context.Context, cfg *Config, args commandArgs) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	o, err := cfg.Profile.withdraw(args.arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%v joined your party!\n", o.label())
+	autosave(cfg)
+	return nil
+}
+
+func commandDeposit(ctx context.Context, cfg *Config, args commandArgs) error {
+	box := 0
+	if v := args.flag("box", ""); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("Box must be a number, got %s", v)
+		}
+		box = n
+	}
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	if err := checkNotBattling(cfg, args.arg(0)); err != nil {
+		return err
+	}
+	o, box, err := cfg.Profile.deposit(args.arg(0), box)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%v was sent to Box %d\n", o.label(), box)
+	autosave(cfg)
+	return nil
+}
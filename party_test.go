@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// profileWith returns a profile owning n Pokemon, pokemon-01 with ID 1 and
+// so on.
+func profileWith(n int) *Profile {
+	profile := newProfile("ash")
+	for i := 1; i <= n; i++ {
+		name := fmt.Sprintf("pokemon-%02d", i)
+		profile.Pokedex[name] = PokedexEntry{Name: name}
+		profile.add(&OwnedPokemon{Pokemon: name, Level: defaultLevel})
+	}
+	return profile
+}
+
+func TestArrangeFillsPartyThenBoxes(t *testing.T) {
+	profile := profileWith(8)
+	if fmt.Sprint(profile.Party) != "[1 2 3 4 5 6]" {
+		t.Fatalf("expected the first six in the party, got %v", profile.Party)
+	}
+	if fmt.Sprint(profile.Boxes) != "[[7 8]]" {
+		t.Errorf("expected the rest in Box 1, got %v", profile.Boxes)
+	}
+
+	// Stale and duplicate entries are dropped.
+	profile.Party = append(profile.Party[:5], 99)
+	profile.Boxes[0] = append(profile.Boxes[0], 1)
+	profile.arrange()
+	if fmt.Sprint(profile.Party) != "[1 2 3 4 5 6]" {
+		t.Errorf("expected ID 99 to be replaced by the unplaced 6, got %v", profile.Party)
+	}
+	if fmt.Sprint(profile.Boxes) != "[[7 8]]" {
+		t.Errorf("expected the duplicate to be dropped from Box 1, got %v", profile.Boxes)
+	}
+}
+
+func TestWithdrawDeposit(t *testing.T) {
+	profile := profileWith(7)
+
+	if _, err := profile.withdraw("pokemon-07"); err == nil {
+		t.Errorf("expected an error when the party is full")
+	}
+	_, box, err := profile.deposit("pokemon-01", 0)
+	if err != nil || box != 1 {
+		t.Fatalf("expected pokemon-01 to go to Box 1, got %v, %v", box, err)
+	}
+	if _, err := profile.withdraw("#7"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.Party[partySize-1] != 7 {
+		t.Errorf("expected pokemon-07 at the end of the party, got %v", profile.Party)
+	}
+	if _, _, err := profile.deposit("pokemon-02", 2); err == nil {
+		t.Errorf("expected an error for a box that does not exist")
+	}
+	if _, _, err := profile.deposit("pokemon-01", 0); err == nil {
+		t.Errorf("expected an error for a Pokemon that is not in the party")
+	}
+
+	single := profileWith(1)
+	if _, _, err := single.deposit("pokemon-01", 0); err == nil {
+		t.Errorf("expected an error when depositing the last party member")
+	}
+}
+
+func TestSwap(t *testing.T) {
+	profile := profileWith(3)
+	if err := profile.swap("1", "pokemon-03"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(profile.Party) != "[3 2 1]" {
+		t.Errorf("expected the first and last to swap, got %v", profile.Party)
+	}
+	if err := profile.swap("1", "4"); err == nil {
+		t.Errorf("expected an error for an empty slot")
+	}
+	if err := profile.swap("pokemon-01", "pikachu"); err == nil {
+		t.Errorf("expected an error for a Pokemon not in the party")
+	}
+}
+
+func TestCatchWithFullParty(t *testing.T) {
+	cfg := newBattleConfig(t)
+	for len(cfg.Profile.Party) < partySize {
+		cfg.Profile.add(&OwnedPokemon{Pokemon: "pikachu", Level: 50, Nature: "hardy"})
+	}
+	ctx := context.Background()
+
+	if err := runCommand(ctx, cfg, commands["battle"], []string{"pidgey"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := runCommand(ctx, cfg, commands["catch"], []string{"pidgey", "--ball", "master-ball"}); err == nil {
+		t.Fatalf("expected an error without a master ball")
+	}
+	cfg.Profile.Bag["master-ball"] = 1
+	if err := runCommand(ctx, cfg, commands["catch"], []string{"pidgey", "--ball", "master-ball"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if box, _ := cfg.Profile.boxSlot(partySize + 1); box != 0 {
+		t.Errorf("expected pidgey to be sent to Box 1, got box index %v", box)
+	}
+}
+
+func TestPartySurvivesSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	profile := profileWith(8)
+	if err := profile.swap("1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSave(path, profile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := readSave(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(loaded.Party) != fmt.Sprint(profile.Party) {
+		t.Errorf("expected party %v, got %v", profile.Party, loaded.Party)
+	}
+	if fmt.Sprint(loaded.Boxes) != "[[7 8]]" {
+		t.Errorf("expected 7 and 8 in Box 1, got %v", loaded.Boxes)
+	}
+}
+
+func TestSwapIsSaved(t *testing.T) {
+	cfg := &Config{DataDir: t.TempDir(), Profile: profileWith(3)}
+	if err := runCommand(context.Background(), cfg, commands["party"], []string{"swap", "1", "3"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	saved, err := readSave(slotPath(cfg.DataDir, cfg.Profile.Name))
+	if err != nil {
+		t.Fatalf("expected the swap to be saved: %v", err)
+	}
+	if fmt.Sprint(saved.Party) != "[3 2 1]" {
+		t.Errorf("expected the saved party to be swapped, got %v", saved.Party)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+// PokedexEntry is what a profile keeps of a kind of Pokemon it has caught:
+// enough for the Pokedex, stats and type matchups. The rest of the PokeAPI
+// data, such as moves and sprites, is fetched through the cache when needed
+// rather than written into every save.
+type PokedexEntry struct {
+	Name    string     `json:"name"`
+	Species string     `json:"species"`
+	Types   []string   `json:"types"`
+	Stats   []BaseStat `json:"stats"`
+}
+
+// BaseStat is one of a kind's base stats and the effort values it yields
+// when defeated.
+type BaseStat struct {
+	Name   string `json:"name"`
+	Base   int    `json:"base"`
+	Effort int    `json:"effort,omitempty"`
+}
+
+func newPokedexEntry(pkm pokeapi.Pokemon) PokedexEntry {
+	entry := PokedexEntry{
+		Name:    pkm.Name,
+		Species: pkm.Species.Name,
+		Types:   pokemonTypes(pkm),
+		Stats:   make([]BaseStat, 0, len(pkm.Stats)),
+	}
+	for _, s := range pkm.Stats {
+		entry.Stats = append(entry.Stats, BaseStat{Name: s.Stat.Name, Base: s.BaseStat, Effort: s.Effort})
+	}
+	return entry
+}
+
+// slimLegacyPokedex replaces the full PokeAPI data that saves before version
+// 7 kept for each caught kind with its PokedexEntry.
+func slimLegacyPokedex(raw []byte) ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	legacy := map[string]pokeapi.Pokemon{}
+	if pokedex, ok := fields["pokedex"]; ok {
+		if err := json.Unmarshal(pokedex, &legacy); err != nil {
+			return nil, err
+		}
+	}
+	entries := make(map[string]PokedexEntry, len(legacy))
+	for name, pkm := range legacy {
+		entry := newPokedexEntry(pkm)
+		if entry.Name == "" {
+			entry.Name = name
+		}
+		entries[name] = entry
+	}
+	pokedex, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	fields["pokedex"] = pokedex
+	return json.Marshal(fields)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultProfileName = "default"
+
+var profileNameRe = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Profile is one trainer's collection. Each profile lives in its own save
+// slot under the profiles directory.
+type Profile struct {
+	Name     string          `json:"name"`
+	Created  time.Time       `json:"created"`
+	PlayTime time.Duration   `json:"play_time"`
+	Seen     map[string]bool `json:"seen"`
+	// Pokedex holds an entry for every kind of Pokemon caught, and Owned
+	// the individual Pokemon, keyed by their ID.
+	Pokedex map[string]PokedexEntry `json:"pokedex"`
+	Owned   map[int]*OwnedPokemon   `json:"owned"`
+	NextID  int                     `json:"next_id"`
+	Bag     Bag                     `json:"bag"`
+	// Party and Boxes hold the IDs of owned Pokemon. Every owned Pokemon
+	// is in exactly one of them.
+	Party []int   `json:"party"`
+	Boxes [][]int `json:"boxes"`
+
+	sessionStart time.Time
+}
+
+func newProfile(name string) *Profile {
+	p := &Profile{
+		Name:    name,
+		Created: time.Now(),
+	}
+	p.init()
+	return p
+}
+
+// init fills in what older saves may lack and starts the play time clock.
+func (p *Profile) init() {
+	if p.Pokedex == nil {
+		p.Pokedex = make(map[string]PokedexEntry)
+	}
+	if p.Seen == nil {
+		p.Seen = make(map[string]bool)
+	}
+	if p.Owned == nil {
+		p.Owned = make(map[int]*OwnedPokemon)
+	}
+	for name := range p.Pokedex {
+		p.Seen[name] = true
+	}
+	if p.Bag == nil {
+		p.Bag = starterBag()
+	}
+	p.arrange()
+	p.sessionStart = time.Now()
+}
+
+// updatePlayTime adds the time since the last update to PlayTime.
+func (p *Profile) updatePlayTime() {
+	now := time.Now()
+	if !p.sessionStart.IsZero() {
+		p.PlayTime += now.Sub(p.sessionStart)
+	}
+	p.sessionStart = now
+}
+
+func profilesDir(dataDir string) string {
+	return filepath.Join(dataDir, "profiles")
+}
+
+func slotPath(dataDir, name string) string {
+	return filepath.Join(profilesDir(dataDir), name+".json")
+}
+
+// readCurrentProfile returns the name of the profile used last, falling back
+// to the default profile.
+func readCurrentProfile(dataDir string) string {
+	raw, err := os.ReadFile(filepath.Join(dataDir, "current-profile"))
+	if err != nil {
+		return defaultProfileName
+	}
+	name := strings.TrimSpace(string(raw))
+	if !profileNameRe.MatchString(name) {
+		return defaultProfileName
+	}
+	return name
+}
+
+func writeCurrentProfile(dataDir, name string) error {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dataDir, "current-profile"), []byte(name+"\n"), 0o644)
+}
+
+// listProfiles reads every profile slot. Slots that cannot be read are
+// skipped and passed to warn, if it is not nil, so one corrupt save does not
+// hide the others.
+func listProfiles(dataDir string, warn func(error)) ([]*Profile, error) {
+	files, err := filepath.Glob(filepath.Join(profilesDir(dataDir), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var profiles []*Profile
+	for _, file := range files {
+		profile, err := readSave(file)
+		if err != nil {
+			if warn != nil {
+				warn(err)
+			}
+			continue
+		}
+		profile.Name = strings.TrimSuffix(filepath.Base(file), ".json")
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+func commandProfile(ctx context.Context, cfg *Config, args commandArgs) error {
+	action, name := args.arg(0), args.arg(1)
+	if action == "" {
+		cfg.mu.Lock()
+		defer cfg.mu.Unlock()
+		cfg.Profile.updatePlayTime()
+		return render(cfg, []record{profileRecord(cfg.Profile, true)}, func() {
+			printProfile(cfg.Profile, true)
+		})
+	}
+	switch action {
+	case "new":
+		if name == "" {
+			return fmt.Errorf("Please provide a profile name")
+		}
+		return newProfileSlot(cfg, name)
+	case "list":
+		return listProfileSlots(cfg)
+	case "switch":
+		if name == "" {
+			return fmt.Errorf("Please provide a profile name")
+		}
+		return switchProfile(cfg, name)
+	default:
+		return fmt.Errorf("Unknown profile action: %s", action)
+	}
+}
+
+func newProfileSlot(cfg *Config, name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("Profile names may only contain lowercase letters, digits, '-' and '_'")
+	}
+	if _, err := os.Stat(slotPath(cfg.DataDir, name)); err == nil {
+		return fmt.Errorf("Profile %s already exists", name)
+	}
+	if err := writeSave(slotPath(cfg.DataDir, name), newProfile(name)); err != nil {
+		return err
+	}
+	fmt.Printf("Created profile %s\n", name)
+	return switchProfile(cfg, name)
+}
+
+func listProfileSlots(cfg *Config) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	// Save first so the current profile is listed with up to date numbers.
+	autosave(cfg)
+	profiles, err := listProfiles(cfg.DataDir, func(err error) {
+		fmt.Printf("Warning: Skipping unreadable profile: %v\n", err)
+	})
+	if err != nil {
+		return err
+	}
+	records := make([]record, 0, len(profiles))
+	for _, profile := range profiles {
+		records = append(records, profileRecord(profile, profile.Name == cfg.Profile.Name))
+	}
+	return render(cfg, records, func() {
+		fmt.Println("Profiles:")
+		for _, profile := range profiles {
+			printProfile(profile, profile.Name == cfg.Profile.Name)
+		}
+	})
+}
+
+// switchProfile saves the current profile and makes name the current one.
+// The current profile is saved before the slot is read, so switching to
+// itself cannot bring back an older copy.
+func switchProfile(cfg *Config, name string) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	if cfg.Profile != nil && cfg.Profile.Name == name {
+		fmt.Printf("Already using profile %s\n", name)
+		return nil
+	}
+	autosave(cfg)
+	profile, err := readSave(slotPath(cfg.DataDir, name))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("No profile named %s", name)
+	}
+	if err != nil {
+		return err
+	}
+	profile.Name = name
+	leaveBattle(cfg)
+	cfg.Profile = profile
+	if err := writeCurrentProfile(cfg.DataDir, name); err != nil {
+		return fmt.Errorf("Failed to remember current profile: %v", err)
+	}
+	fmt.Printf("Switched to profile %s\n", name)
+	return nil
+}
+
+func printProfile(p *Profile, current bool) {
+	marker := " "
+	if current {
+		marker = "*"
+	}
+	fmt.Printf("%s %v\n", marker, p.Name)
+	fmt.Printf("   - Created: %v\n", p.Created.Format("2006-01-02"))
+	fmt.Printf("   - Play time: %v\n", p.PlayTime.Round(time.Second))
+	fmt.Printf("   - Caught: %v (%v kinds)\n", len(p.Owned), len(p.Pokedex))
+	fmt.Printf("   - Seen: %v\n", len(p.Seen))
+}
+
+func profileRecord(p *Profile, current bool) record {
+	return record{
+		{"name", p.Name},
+		{"current", current},
+		{"created", p.Created.Format("2006-01-02")},
+		{"play_time", p.PlayTime.Round(time.Second).String()},
+		{"caught", len(p.Owned)},
+		{"kinds", len(p.Pokedex)},
+		{"seen", len(p.Seen)},
+	}
+}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/UUest/pokecli/internal/lineedit"
 )
 
 func TestCleanInput(t *testing.T) {
@@ -17,10 +22,18 @@ func TestCleanInput(t *testing.T) {
 			input:    "heya erf",
 			expected: []string{"heya", "erf"},
 		},
+		{
+			input:    "  SAVE /tmp/Saves/Backup.json ",
+			expected: []string{"save", "/tmp/Saves/Backup.json"},
+		},
 	}
 
 	for _, c := range cases {
 		actual := CleanInput(c.input)
+		if len(actual) != len(c.expected) {
+			t.Errorf("Expected %v, got %v", c.expected, actual)
+			continue
+		}
 		for i := range actual {
 			word := actual[i]
 			expectedWord := c.expected[i]
@@ -30,3 +43,42 @@ func TestCleanInput(t *testing.T) {
 		}
 	}
 }
+
+func TestRunLinesScript(t *testing.T) {
+	cases := []struct {
+		script string
+		status int
+	}{
+		{script: "help\n\n# a comment\nhelp", status: 0},
+		{script: "help\nbogus\nhelp\n", status: 1},
+		{script: "catch\n", status: 1},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), "session.txt")
+		if err := os.WriteFile(path, []byte(c.script), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := &Config{Profile: newProfile("ash")}
+		status := runLines(cfg, &interrupter{}, lineedit.New(f, io.Discard))
+		f.Close()
+		if status != c.status {
+			t.Errorf("%q: expected status %v, got %v", c.script, c.status, status)
+		}
+	}
+}
+
+func TestRunWordsKeepsSpaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "My Saves", "Backup.json")
+	cfg := &Config{Profile: newProfile("ash")}
+	if status, _ := runWords(cfg, &interrupter{}, []string{"SAVE", path}, false); status != 0 {
+		t.Fatalf("expected status 0, got %v", status)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the save at %v: %v", path, err)
+	}
+}
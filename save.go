@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// saveVersion is bumped whenever the layout of saveFile changes so that
+// older binaries refuse newer saves instead of silently dropping data.
+//
+// Version 1 only held the Pokedex. Version 2 wraps it in a trainer Profile;
+// version 1 files still load, as a profile without metadata. Version 3 adds
+// the Bag; older saves get the starter bag. Version 4 adds the Party and PC
+// Boxes; caught Pokemon from older saves fill the party, then the boxes.
+// Version 5 adds Owned Pokemon, and the party and boxes hold their IDs
+// instead of names; each caught Pokemon of an older save becomes one owned
+// Pokemon. Version 6 adds EVs to owned Pokemon; older ones have none.
+// Version 7 keeps only a PokedexEntry per caught kind instead of its full
+// PokeAPI data, which older saves are slimmed down to.
+const saveVersion = 7
+
+type saveFile struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	Profile
+}
+
+// defaultDataDir is where profiles and their save slots live.
+func defaultDataDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".pokecli"
+	}
+	return filepath.Join(dir, "pokecli")
+}
+
+// writeSave stores the profile at path, going through a temporary file so an
+// interrupted write never corrupts an existing save.
+func writeSave(path string, profile *Profile) error {
+	profile.updatePlayTime()
+	raw, err := json.Marshal(saveFile{
+		Version: saveVersion,
+		SavedAt: time.Now(),
+		Profile: *profile,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to encode save data: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("Failed to create save directory: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("Failed to write save file: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("Failed to write save file: %v", err)
+	}
+	return nil
+}
+
+func readSave(path string) (*Profile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	header := struct {
+		Version int `json:"version"`
+	}{}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("Failed to parse save file %s: %v", path, err)
+	}
+	if header.Version > saveVersion {
+		return nil, fmt.Errorf("save file %s is version %d, this pokecli only understands up to version %d", path, header.Version, saveVersion)
+	}
+	var legacy legacyStorage
+	if header.Version < 5 {
+		if raw, legacy, err = splitLegacyStorage(raw); err != nil {
+			return nil, fmt.Errorf("Failed to parse save file %s: %v", path, err)
+		}
+	}
+	if header.Version < 7 {
+		if raw, err = slimLegacyPokedex(raw); err != nil {
+			return nil, fmt.Errorf("Failed to parse save file %s: %v", path, err)
+		}
+	}
+	save := saveFile{}
+	if err := json.Unmarshal(raw, &save); err != nil {
+		return nil, fmt.Errorf("Failed to parse save file %s: %v", path, err)
+	}
+	profile := &save.Profile
+	if header.Version < 5 {
+		profile.Owned = make(map[int]*OwnedPokemon)
+		profile.adoptLegacy(legacy.Party, legacy.Boxes)
+	}
+	profile.init()
+	return profile, nil
+}
+
+// legacyStorage is the party and boxes of a version 4 save, which hold
+// Pokemon names rather than IDs.
+type legacyStorage struct {
+	Party []string   `json:"party"`
+	Boxes [][]string `json:"boxes"`
+}
+
+// splitLegacyStorage takes the name-based party and boxes out of an older
+// save so that the rest decodes into the current layout.
+func splitLegacyStorage(raw []byte) ([]byte, legacyStorage, error) {
+	var legacy legacyStorage
+	if err := json.Unmarshal(raw, &legacy); err != nil {
+		return nil, legacyStorage{}, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, legacyStorage{}, err
+	}
+	delete(fields, "party")
+	delete(fields, "boxes")
+	raw, err := json.Marshal(fields)
+	return raw, legacy, err
+}
+
+// autosave writes the current profile to its save slot, warning instead of
+// failing. cfg.mu must be held.
+func autosave(cfg *Config) {
+	if err := saveProfile(cfg); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+// saveProfile writes the current profile to its save slot. Without a data
+// directory there is nowhere to save and it does nothing. cfg.mu must be
+// held.
+func saveProfile(cfg *Config) error {
+	if cfg.DataDir == "" || cfg.Profile == nil {
+		return nil
+	}
+	return writeSave(slotPath(cfg.DataDir, cfg.Profile.Name), cfg.Profile)
+}
+
+// loadOnStartup restores the last used profile from its save slot, creating
+// it if it does not exist yet. A version 1 save.json from before profiles
+// existed is adopted as the default profile.
+func loadOnStartup(cfg *Config) error {
+	name := readCurrentProfile(cfg.DataDir)
+	profile, err := readSave(slotPath(cfg.DataDir, name))
+	if errors.Is(err, os.ErrNotExist) && name == defaultProfileName {
+		profile, err = readSave(filepath.Join(cfg.DataDir, "save.json"))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		cfg.Profile = newProfile(name)
+		return nil
+	}
+	if err != nil {
+		cfg.Profile = newProfile(name)
+		return err
+	}
+	profile.Name = name
+	cfg.Profile = profile
+	return nil
+}
+
+func commandSave(ctx context.Context, cfg *Config, args commandArgs) error {
+	path := slotPath(cfg.DataDir, cfg.Profile.Name)
+	if file := args.arg(0); file != "" {
+		path = file
+	}
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	if err := writeSave(path, cfg.Profile); err != nil {
+		return err
+	}
+	fmt.Printf("Pokedex saved to %s\n", path)
+	return nil
+}
+
+// commandLoad replaces the current profile's collection and bag with the
+// ones in file, keeping the trainer's name, creation date and play time.
+func commandLoad(ctx context.Context, cfg *Config, args commandArgs) error {
+	file := args.arg(0)
+	loaded, err := readSave(file)
+	if err != nil {
+		return err
+	}
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	leaveBattle(cfg)
+	cfg.Profile.Pokedex = loaded.Pokedex
+	cfg.Profile.Seen = loaded.Seen
+	cfg.Profile.Owned = loaded.Owned
+	cfg.Profile.NextID = loaded.NextID
+	cfg.Profile.Party = loaded.Party
+	cfg.Profile.Boxes = loaded.Boxes
+	cfg.Profile.Bag = loaded.Bag
+	fmt.Printf("Loaded %d Pokemon from %s\n", len(loaded.Owned), file)
+	autosave(cfg)
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "save.json")
+	profile := newProfile("ash")
+	profile.Pokedex["pikachu"] = PokedexEntry{Name: "pikachu", Types: []string{"electric"}}
+	profile.Seen["pidgey"] = true
+	if err := writeSave(path, profile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := readSave(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Name != "ash" || !loaded.Created.Equal(profile.Created) {
+		t.Errorf("expected profile metadata to survive the round trip, got %v %v", loaded.Name, loaded.Created)
+	}
+	if entry, ok := loaded.Pokedex["pikachu"]; !ok || len(entry.Types) != 1 || entry.Types[0] != "electric" {
+		t.Errorf("expected pikachu to survive the round trip, got %v", loaded.Pokedex)
+	}
+	if !loaded.Seen["pidgey"] || !loaded.Seen["pikachu"] {
+		t.Errorf("expected pidgey and pikachu to be seen, got %v", loaded.Seen)
+	}
+}
+
+func TestReadSaveVersionOne(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1, "pokedex": {"pikachu": {"name": "pikachu"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := readSave(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := loaded.Pokedex["pikachu"]; !ok {
+		t.Errorf("expected version 1 Pokedex to load, got %v", loaded.Pokedex)
+	}
+}
+
+func TestReadSaveRejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := os.WriteFile(path, []byte(`{"version": 999, "pokedex": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSave(path); err == nil {
+		t.Errorf("expected an error for a newer save version")
+	}
+}
+
+func TestSwitchProfile(t *testing.T) {
+	cfg := &Config{DataDir: t.TempDir()}
+	if err := loadOnStartup(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.Profile.Pokedex["pikachu"] = PokedexEntry{Name: "pikachu"}
+
+	if err := commandProfile(context.Background(), cfg, newArgs("new", "misty")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Profile.Name != "misty" || len(cfg.Profile.Pokedex) != 0 {
+		t.Errorf("expected an empty misty profile, got %v with %v", cfg.Profile.Name, cfg.Profile.Pokedex)
+	}
+
+	restarted := &Config{DataDir: cfg.DataDir}
+	if err := loadOnStartup(restarted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restarted.Profile.Name != "misty" {
+		t.Errorf("expected misty to be the current profile, got %v", restarted.Profile.Name)
+	}
+	if err := commandProfile(context.Background(), restarted, newArgs("switch", "default")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := restarted.Profile.Pokedex["pikachu"]; !ok {
+		t.Errorf("expected default profile to keep pikachu, got %v", restarted.Profile.Pokedex)
+	}
+}
+
+func TestSwitchToCurrentProfile(t *testing.T) {
+	cfg := &Config{DataDir: t.TempDir()}
+	if err := loadOnStartup(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	profile := profileWith(3)
+	profile.Name = cfg.Profile.Name
+	cfg.Profile = profile
+	autosave(cfg)
+	if err := profile.swap("1", "3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := commandProfile(context.Background(), cfg, newArgs("switch", profile.Name)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(cfg.Profile.Party) != "[3 2 1]" {
+		t.Errorf("expected the swapped party to be kept, got %v", cfg.Profile.Party)
+	}
+}
+
+func TestListProfilesSkipsUnreadableSlots(t *testing.T) {
+	dataDir := t.TempDir()
+	for _, name := range []string{"ash", "misty"} {
+		if err := writeSave(slotPath(dataDir, name), newProfile(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(slotPath(dataDir, "brock"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []error
+	profiles, err := listProfiles(dataDir, func(err error) {
+		warnings = append(warnings, err)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profiles) != 2 || profiles[0].Name != "ash" || profiles[1].Name != "misty" {
+		t.Errorf("expected ash and misty, got %v profiles", len(profiles))
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected one warning for the corrupt slot, got %v", warnings)
+	}
+}
+
+func TestLoadReplacesBag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	saved := newProfile("ash")
+	saved.Bag = Bag{"ultra-ball": 3}
+	if err := writeSave(path, saved); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Profile: newProfile("misty")}
+	if err := runCommand(context.Background(), cfg, commands["load"], []string{path}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Profile.Bag) != 1 || cfg.Profile.Bag["ultra-ball"] != 3 {
+		t.Errorf("expected the saved bag, got %v", cfg.Profile.Bag)
+	}
+	if cfg.Profile.Name != "misty" {
+		t.Errorf("expected to keep the trainer's name, got %v", cfg.Profile.Name)
+	}
+}
+
+func TestReadSaveSlimsLegacyPokedex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	raw := `{"version": 6, "pokedex": {"pikachu": {"name": "pikachu", "species": {"name": "pikachu"}, "height": 4,
+		"types": [{"slot": 1, "type": {"name": "electric"}}],
+		"stats": [{"base_stat": 35, "effort": 0, "stat": {"name": "hp"}}, {"base_stat": 90, "effort": 2, "stat": {"name": "speed"}}],
+		"moves": [{"move": {"name": "thunder-shock"}, "version_group_details": [{"level_learned_at": 1}]}],
+		"sprites": {"front_default": "https://example.com/pikachu.png"}}}}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := readSave(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entry := loaded.Pokedex["pikachu"]
+	if entry.Name != "pikachu" || entry.Species != "pikachu" || len(entry.Types) != 1 || entry.Types[0] != "electric" {
+		t.Errorf("expected pikachu's name, species and type to be kept, got %+v", entry)
+	}
+	if len(entry.Stats) != 2 || entry.Stats[1] != (BaseStat{Name: "speed", Base: 90, Effort: 2}) {
+		t.Errorf("expected pikachu's base stats and effort to be kept, got %+v", entry.Stats)
+	}
+
+	if err := writeSave(path, loaded); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dropped := range []string{"thunder-shock", "example.com", "height"} {
+		if strings.Contains(string(saved), dropped) {
+			t.Errorf("expected %q to be dropped from the save", dropped)
+		}
+	}
+}
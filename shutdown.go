@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// errExit is returned by the exit command to ask the REPL loop to stop.
+var errExit = errors.New("exit requested")
+
+// shutdownHooks collects the cleanup that must happen before the program
+// exits, however it exits.
+type shutdownHooks struct {
+	mu    sync.Mutex
+	hooks []shutdownHook
+	done  bool
+}
+
+type shutdownHook struct {
+	name string
+	fn   func() error
+}
+
+// newShutdownHooks registers the cleanup every run needs: stopping the
+// cache reaper and saving the profile.
+func newShutdownHooks(cfg *Config) *shutdownHooks {
+	hooks := &shutdownHooks{}
+	hooks.add("stop the cache", func() error {
+		if cfg.Cache != nil {
+			cfg.Cache.Close()
+		}
+		return nil
+	})
+	hooks.add("save the profile", func() error {
+		cfg.mu.Lock()
+		defer cfg.mu.Unlock()
+		return saveProfile(cfg)
+	})
+	return hooks
+}
+
+// add registers fn under a name used in warnings when it fails.
+func (s *shutdownHooks) add(name string, fn func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// run calls the hooks in reverse order of registration, so later hooks may
+// depend on what earlier ones set up. A failing hook does not stop the
+// others. Only the first call does anything.
+func (s *shutdownHooks) run() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return nil
+	}
+	s.done = true
+	var errs []error
+	for i := len(s.hooks) - 1; i >= 0; i-- {
+		if err := s.hooks[i].fn(); err != nil {
+			errs = append(errs, fmt.Errorf("Failed to %s: %w", s.hooks[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UUest/pokecli/internal/lineedit"
+	"github.com/UUest/pokecli/internal/pokecache"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	var order []string
+	hooks := &shutdownHooks{}
+	hooks.add("first", func() error {
+		order = append(order, "first")
+		return nil
+	})
+	hooks.add("second", func() error {
+		order = append(order, "second")
+		return errors.New("disk full")
+	})
+	hooks.add("third", func() error {
+		order = append(order, "third")
+		return nil
+	})
+
+	err := hooks.run()
+	if err == nil || !strings.Contains(err.Error(), "Failed to second: disk full") {
+		t.Errorf("expected the failing hook to be reported, got %v", err)
+	}
+	if strings.Join(order, ",") != "third,second,first" {
+		t.Errorf("expected hooks to run in reverse order, got %v", order)
+	}
+	if err := hooks.run(); err != nil || len(order) != 3 {
+		t.Errorf("expected a second run to do nothing, got %v and %v", err, order)
+	}
+}
+
+func TestExitRunsShutdown(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		Cache:   pokecache.NewCache(time.Minute),
+		DataDir: dir,
+		Profile: newProfile("ash"),
+	}
+	cfg.Profile.Seen["pidgey"] = true
+	hooks := newShutdownHooks(cfg)
+
+	path := filepath.Join(dir, "session.txt")
+	if err := os.WriteFile(path, []byte("exit\nbogus\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	status := runLines(cfg, &interrupter{}, lineedit.New(f, io.Discard))
+	if status != 0 {
+		t.Errorf("expected exit to stop the run before the unknown command, got status %v", status)
+	}
+	if status := exitWith(hooks, status); status != 0 {
+		t.Errorf("expected status 0 after shutdown, got %v", status)
+	}
+
+	profile, err := readSave(slotPath(dir, "ash"))
+	if err != nil {
+		t.Fatalf("expected the profile to be saved on shutdown: %v", err)
+	}
+	if !profile.Seen["pidgey"] {
+		t.Errorf("expected the saved profile to include seen Pokemon")
+	}
+}
+
+func TestFailingHookFailsExit(t *testing.T) {
+	hooks := &shutdownHooks{}
+	hooks.add("save the profile", func() error { return errors.New("read-only file system") })
+	if status := exitWith(hooks, 0); status != 1 {
+		t.Errorf("expected status 1 when a hook fails, got %v", status)
+	}
+	hooks = &shutdownHooks{}
+	hooks.add("save the profile", func() error { return errors.New("read-only file system") })
+	if status := exitWith(hooks, 130); status != 130 {
+		t.Errorf("expected the original status to be kept, got %v", status)
+	}
+}
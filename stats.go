@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+// statLabels are the display names of statNames.
+var statLabels = map[string]string{
+	"hp":              "HP",
+	"attack":          "Attack",
+	"defense":         "Defense",
+	"special-attack":  "Special Attack",
+	"special-defense": "Special Defense",
+	"speed":           "Speed",
+}
+
+// baseStats returns a Pokemon's base stats by stat name.
+func baseStats(entry PokedexEntry) map[string]int {
+	stats := make(map[string]int, len(entry.Stats))
+	for _, s := range entry.Stats {
+		stats[s.Name] = s.Base
+	}
+	return stats
+}
+
+// natureModifier returns the effect of nature on stat in percent: 110 for
+// the stat it raises, 90 for the one it lowers and 100 otherwise.
+func natureModifier(nature pokeapi.Nature, stat string) int {
+	up, down := "", ""
+	if nature.IncreasedStat != nil {
+		up = nature.IncreasedStat.Name
+	}
+	if nature.DecreasedStat != nil {
+		down = nature.DecreasedStat.Name
+	}
+	switch {
+	case up == down:
+		return 100
+	case stat == up:
+		return 110
+	case stat == down:
+		return 90
+	}
+	return 100
+}
+
+// computeStat applies the stat formulas of generation III onwards. HP does
+// not depend on nature, and a base HP of 1 (Shedinja) always gives 1 HP.
+func computeStat(stat string, base, iv, ev, level, modifier int) int {
+	core := (2*base + iv + ev/4) * level / 100
+	if stat == "hp" {
+		if base == 1 {
+			return 1
+		}
+		return core + level + 10
+	}
+	return (core + 5) * modifier / 100
+}
+
+// statLine is one stat of an owned Pokemon.
+type statLine struct {
+	name                string
+	base, iv, ev, value int
+}
+
+// computeStats works out the stats of o, matching the base stats of its kind
+// by name. Stats its kind has no base value for are left out.
+func computeStats(entry PokedexEntry, o *OwnedPokemon, nature pokeapi.Nature) []statLine {
+	bases := baseStats(entry)
+	lines := make([]statLine, 0, len(statNames))
+	for _, stat := range statNames {
+		base, ok := bases[stat]
+		if !ok {
+			continue
+		}
+		line := statLine{name: stat, base: base, iv: o.IVs[stat], ev: o.EVs[stat]}
+		line.value = computeStat(stat, base, line.iv, line.ev, o.Level, natureModifier(nature, stat))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func printStats(lines []statLine) {
+	fmt.Println("Stats:")
+	for _, line := range lines {
+		fmt.Printf("   - %v: %v (base %v, IV %v, EV %v)\n", statLabels[line.name], line.value, line.base, line.iv, line.ev)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+func testNature(t *testing.T, raw string) pokeapi.Nature {
+	t.Helper()
+	nature := pokeapi.Nature{}
+	if err := json.Unmarshal([]byte(raw), &nature); err != nil {
+		t.Fatal(err)
+	}
+	return nature
+}
+
+func TestComputeStat(t *testing.T) {
+	// The level 78 Adamant Garchomp worked through on Bulbapedia, plus the
+	// edge cases of the formulas.
+	cases := []struct {
+		name     string
+		stat     string
+		base     int
+		iv       int
+		ev       int
+		level    int
+		modifier int
+		expected int
+	}{
+		{name: "garchomp hp", stat: "hp", base: 108, iv: 24, ev: 74, level: 78, modifier: 100, expected: 289},
+		{name: "garchomp attack, raised", stat: "attack", base: 130, iv: 12, ev: 190, level: 78, modifier: 110, expected: 278},
+		{name: "garchomp defense", stat: "defense", base: 95, iv: 30, ev: 91, level: 78, modifier: 100, expected: 193},
+		{name: "garchomp special attack, lowered", stat: "special-attack", base: 80, iv: 16, ev: 48, level: 78, modifier: 90, expected: 135},
+		{name: "garchomp special defense", stat: "special-defense", base: 85, iv: 23, ev: 84, level: 78, modifier: 100, expected: 171},
+		{name: "garchomp speed", stat: "speed", base: 102, iv: 5, ev: 23, level: 78, modifier: 100, expected: 171},
+		{name: "shedinja hp", stat: "hp", base: 1, iv: 31, ev: 252, level: 100, modifier: 100, expected: 1},
+		{name: "level 1 hp", stat: "hp", base: 45, iv: 0, ev: 0, level: 1, modifier: 100, expected: 11},
+		{name: "maxed speed", stat: "speed", base: 180, iv: 31, ev: 252, level: 100, modifier: 110, expected: 504},
+	}
+
+	for _, c := range cases {
+		if actual := computeStat(c.stat, c.base, c.iv, c.ev, c.level, c.modifier); actual != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestNatureModifier(t *testing.T) {
+	adamant := testNature(t, `{"name": "adamant", "increased_stat": {"name": "attack"}, "decreased_stat": {"name": "special-attack"}}`)
+	hardy := testNature(t, `{"name": "hardy", "increased_stat": {"name": "attack"}, "decreased_stat": {"name": "attack"}}`)
+	unknown := pokeapi.Nature{}
+
+	cases := []struct {
+		nature   pokeapi.Nature
+		stat     string
+		expected int
+	}{
+		{nature: adamant, stat: "attack", expected: 110},
+		{nature: adamant, stat: "special-attack", expected: 90},
+		{nature: adamant, stat: "speed", expected: 100},
+		{nature: hardy, stat: "attack", expected: 100},
+		{nature: unknown, stat: "attack", expected: 100},
+	}
+
+	for _, c := range cases {
+		if actual := natureModifier(c.nature, c.stat); actual != c.expected {
+			t.Errorf("%v %v: expected %v, got %v", c.nature.Name, c.stat, c.expected, actual)
+		}
+	}
+}
+
+func TestComputeStatsMatchesByName(t *testing.T) {
+	// Stats deliberately out of order and without special defense.
+	pkm := pokeapi.Pokemon{}
+	raw := `{"name": "garchomp", "stats": [
+		{"base_stat": 102, "stat": {"name": "speed"}},
+		{"base_stat": 108, "stat": {"name": "hp"}},
+		{"base_stat": 130, "stat": {"name": "attack"}},
+		{"base_stat": 95, "stat": {"name": "defense"}},
+		{"base_stat": 80, "stat": {"name": "special-attack"}}
+	]}`
+	if err := json.Unmarshal([]byte(raw), &pkm); err != nil {
+		t.Fatal(err)
+	}
+	o := &OwnedPokemon{
+		Level: 78,
+		IVs:   map[string]int{"hp": 24, "attack": 12, "defense": 30, "special-attack": 16, "special-defense": 23, "speed": 5},
+		EVs:   map[string]int{"hp": 74, "attack": 190, "defense": 91, "special-attack": 48, "special-defense": 84, "speed": 23},
+	}
+	adamant := testNature(t, `{"name": "adamant", "increased_stat": {"name": "attack"}, "decreased_stat": {"name": "special-attack"}}`)
+
+	expected := map[string]int{"hp": 289, "attack": 278, "defense": 193, "special-attack": 135, "speed": 171}
+	lines := computeStats(newPokedexEntry(pkm), o, adamant)
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %v stats, got %v", len(expected), lines)
+	}
+	for i, line := range lines {
+		if line.value != expected[line.name] {
+			t.Errorf("%v: expected %v, got %v", line.name, expected[line.name], line.value)
+		}
+		if i > 0 && line.name == "hp" {
+			t.Errorf("expected stats in the games' order, got %v", lines)
+		}
+	}
+}
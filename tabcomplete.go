@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+// completeLine returns the tab completion candidates for the word being
+// typed at the end of line: command names for the first word, otherwise
+// whatever the command's complete hook offers.
+func completeLine(cfg *Config, line string) []string {
+	words := strings.Fields(line)
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	command, ok := commands[words[0]]
+	if !ok || command.complete == nil {
+		return nil
+	}
+	// Completion must never leave the prompt hanging on a slow network.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return command.complete(ctx, cfg, words[1:])
+}
+
+// completeWords offers a fixed list for the first argument.
+func completeWords(words ...string) func(context.Context, *Config, []string) []string {
+	return func(ctx context.Context, cfg *Config, args []string) []string {
+		if len(args) == 0 {
+			return words
+		}
+		return nil
+	}
+}
+
+func resourceNames(list pokeapi.NamedResourceList, err error) []string {
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(list.Results))
+	for _, result := range list.Results {
+		names = append(names, result.Name)
+	}
+	return names
+}
+
+func completeExplore(ctx context.Context, cfg *Config, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	return resourceNames(cfg.Client.ListAllLocationAreas(ctx))
+}
+
+func completeCatch(ctx context.Context, cfg *Config, args []string) []string {
+	if len(args) > 0 && args[len(args)-1] == "--ball" {
+		cfg.mu.RLock()
+		defer cfg.mu.RUnlock()
+		var balls []string
+		for item := range cfg.Profile.Bag {
+			if _, ok := ballModifiers[item]; ok {
+				balls = append(balls, item)
+			}
+		}
+		sort.Strings(balls)
+		return balls
+	}
+	named, ball := false, false
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--ball":
+			ball = true
+			i++ // skip the ball's name
+		case !strings.HasPrefix(args[i], "--"):
+			named = true
+		}
+	}
+	switch {
+	case !named:
+		return resourceNames(cfg.Client.ListPokemon(ctx))
+	case !ball:
+		return []string{"--ball"}
+	}
+	return nil
+}
+
+func completeInspect(ctx context.Context, cfg *Config, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return ownedRefs(cfg.Profile, cfg.Profile.ownedByID())
+}
+
+// ownedRefs returns how to refer to each of owned, sorted.
+func ownedRefs(p *Profile, owned []*OwnedPokemon) []string {
+	refs := make([]string, 0, len(owned))
+	for _, o := range owned {
+		refs = append(refs, p.ref(o))
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+func completeProfile(ctx context.Context, cfg *Config, args []string) []string {
+	switch {
+	case len(args) == 0:
+		return []string{"new", "list", "switch"}
+	case len(args) == 1 && args[0] == "switch":
+		profiles, err := listProfiles(cfg.DataDir, nil)
+		if err != nil {
+			return nil
+		}
+		names := make([]string, 0, len(profiles))
+		for _, profile := range profiles {
+			names = append(names, profile.Name)
+		}
+		return names
+	}
+	return nil
+}
+
+func completeSet(ctx context.Context, cfg *Config, args []string) []string {
+	switch {
+	case len(args) == 0:
+		return []string{"output"}
+	case len(args) == 1 && args[0] == "output":
+		return outputFormats
+	}
+	return nil
+}
+
+func completeParty(ctx context.Context, cfg *Config, args []string) []string {
+	switch {
+	case len(args) == 0:
+		return []string{"add", "remove", "swap"}
+	case args[0] == "add" && len(args) == 1:
+		return completeWithdraw(ctx, cfg, nil)
+	case args[0] == "remove" && len(args) == 1, args[0] == "swap" && len(args) < 3:
+		return completeDeposit(ctx, cfg, nil)
+	}
+	return nil
+}
+
+// completeWithdraw offers the Pokemon stored in the PC.
+func completeWithdraw(ctx context.Context, cfg *Config, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	var owned []*OwnedPokemon
+	for _, box := range cfg.Profile.Boxes {
+		for _, id := range box {
+			owned = append(owned, cfg.Profile.Owned[id])
+		}
+	}
+	return ownedRefs(cfg.Profile, owned)
+}
+
+// completeDeposit offers the party members.
+func completeDeposit(ctx context.Context, cfg *Config, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	owned := make([]*OwnedPokemon, 0, len(cfg.Profile.Party))
+	for _, id := range cfg.Profile.Party {
+		owned = append(owned, cfg.Profile.Owned[id])
+	}
+	return ownedRefs(cfg.Profile, owned)
+}
+
+// completeFight offers the moves of the Pokemon in battle.
+func completeFight(ctx context.Context, cfg *Config, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	if cfg.Battle == nil {
+		return nil
+	}
+	return cfg.Battle.player.moveNames()
+}
+
+// completeExplorePokemon offers the Pokemon met at the location explored
+// last, or every Pokemon before anything was explored.
+func completeExplorePokemon(ctx context.Context, cfg *Config, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	cfg.mu.RLock()
+	names := make([]string, 0, len(cfg.Encounters))
+	for name := range cfg.Encounters {
+		names = append(names, name)
+	}
+	cfg.mu.RUnlock()
+	if len(names) == 0 {
+		return resourceNames(cfg.Client.ListPokemon(ctx))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func completeType(ctx context.Context, cfg *Config, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	chart, err := loadTypeChart(ctx, cfg)
+	if err != nil {
+		return nil
+	}
+	return chart.types
+}
+
+// completeWeak offers the Pokemon in the Pokedex and those met at the
+// location explored last.
+func completeWeak(ctx context.Context, cfg *Config, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	seen := make(map[string]bool)
+	var names []string
+	for name := range cfg.Profile.Pokedex {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range cfg.Encounters {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func completionHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/location-area":
+		fmt.Fprint(w, `{"results": [{"name": "canalave-city-area"}, {"name": "eterna-city-area"}]}`)
+	case "/pokemon":
+		fmt.Fprint(w, `{"results": [{"name": "pidgey"}, {"name": "rattata"}]}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestCompleteLine(t *testing.T) {
+	cfg := newTestConfig(t, completionHandler)
+	cfg.DataDir = t.TempDir()
+	for _, name := range []string{"ash", "misty"} {
+		if err := writeSave(slotPath(cfg.DataDir, name), newProfile(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Six party members, the first nicknamed, and a seventh in the PC.
+	cfg.Profile = profileWith(7)
+	cfg.Profile.Owned[1].Nickname = "sparky"
+	cfg.Profile.Bag = Bag{"poke-ball": 5, "great-ball": 1, "potion": 2}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	party := "pokemon-02,pokemon-03,pokemon-04,pokemon-05,pokemon-06,sparky"
+
+	cases := []struct {
+		line     string
+		expected string
+	}{
+		{line: "", expected: strings.Join(names, ",")},
+		{line: "ca", expected: strings.Join(names, ",")},
+		{line: "explore ", expected: "canalave-city-area,eterna-city-area"},
+		{line: "explore canalave-city-area ", expected: ""},
+		{line: "catch ", expected: "pidgey,rattata"},
+		{line: "catch pid", expected: "pidgey,rattata"},
+		{line: "catch pidgey ", expected: "--ball"},
+		{line: "catch pidgey --ball ", expected: "great-ball,poke-ball"},
+		{line: "catch --ball great-ball ", expected: "pidgey,rattata"},
+		{line: "catch --ball great-ball pidgey ", expected: ""},
+		{line: "inspect ", expected: "pokemon-02,pokemon-03,pokemon-04,pokemon-05,pokemon-06,pokemon-07,sparky"},
+		{line: "party ", expected: "add,remove,swap"},
+		{line: "party add ", expected: "pokemon-07"},
+		{line: "party remove ", expected: party},
+		{line: "party swap sparky ", expected: party},
+		{line: "party swap sparky pokemon-02 ", expected: ""},
+		{line: "withdraw ", expected: "pokemon-07"},
+		{line: "deposit ", expected: party},
+		{line: "profile ", expected: "new,list,switch"},
+		{line: "profile switch ", expected: "ash,misty"},
+		{line: "set output ", expected: strings.Join(outputFormats, ",")},
+		{line: "help ", expected: ""},
+		{line: "bogus ", expected: ""},
+	}
+
+	for _, c := range cases {
+		if actual := strings.Join(completeLine(cfg, c.line), ","); actual != c.expected {
+			t.Errorf("%q: expected %v, got %v", c.line, c.expected, actual)
+		}
+	}
+}
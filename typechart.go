@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+// typeChart holds the damage multiplier of every attacking type against
+// every defending type, built from the PokeAPI's damage relations.
+type typeChart struct {
+	// types are the type names in the PokeAPI's order, which is the games'.
+	types       []string
+	multipliers map[string]map[string]float64
+}
+
+// newTypeChart builds a chart from the given types. Types without any damage
+// relations, such as "unknown" and "shadow", are left out since no Pokemon
+// has them.
+func newTypeChart(types []pokeapi.Type) *typeChart {
+	kept := make([]pokeapi.Type, 0, len(types))
+	for _, t := range types {
+		r := t.DamageRelations
+		if len(r.DoubleDamageTo)+len(r.HalfDamageTo)+len(r.NoDamageTo)+
+			len(r.DoubleDamageFrom)+len(r.HalfDamageFrom)+len(r.NoDamageFrom) > 0 {
+			kept = append(kept, t)
+		}
+	}
+	chart := &typeChart{multipliers: make(map[string]map[string]float64, len(kept))}
+	for _, t := range kept {
+		chart.types = append(chart.types, t.Name)
+	}
+	for _, t := range kept {
+		row := make(map[string]float64, len(chart.types))
+		for _, defender := range chart.types {
+			row[defender] = effectiveness(t, []string{defender})
+		}
+		chart.multipliers[t.Name] = row
+	}
+	return chart
+}
+
+// effectiveness returns the damage multiplier of a move of attackType
+// against a Pokemon of the defending types.
+func effectiveness(attackType pokeapi.Type, defending []string) float64 {
+	multiplier := 1.0
+	for _, defender := range defending {
+		for _, t := range attackType.DamageRelations.DoubleDamageTo {
+			if t.Name == defender {
+				multiplier *= 2
+			}
+		}
+		for _, t := range attackType.DamageRelations.HalfDamageTo {
+			if t.Name == defender {
+				multiplier /= 2
+			}
+		}
+		for _, t := range attackType.DamageRelations.NoDamageTo {
+			if t.Name == defender {
+				multiplier = 0
+			}
+		}
+	}
+	return multiplier
+}
+
+func (c *typeChart) has(name string) bool {
+	for _, t := range c.types {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// multiplier returns the damage multiplier of a move of the attacking type
+// against a Pokemon of the defending types. Unknown types deal normal damage.
+func (c *typeChart) multiplier(attacking string, defending []string) float64 {
+	m := 1.0
+	for _, defender := range defending {
+		if v, ok := c.multipliers[attacking][defender]; ok {
+			m *= v
+		}
+	}
+	return m
+}
+
+// multiplierGroup is the types that share a damage multiplier.
+type multiplierGroup struct {
+	multiplier float64
+	types      []string
+}
+
+// group collects the chart's types by the multiplier of returns for each,
+// highest first, leaving out the types at 1x.
+func (c *typeChart) group(of func(t string) float64) []multiplierGroup {
+	var groups []multiplierGroup
+	for _, m := range []float64{4, 2, 0.5, 0.25, 0} {
+		g := multiplierGroup{multiplier: m}
+		for _, t := range c.types {
+			if of(t) == m {
+				g.types = append(g.types, t)
+			}
+		}
+		if len(g.types) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// label formats a multiplier as it is shown in the games, such as "0.5x".
+func (g multiplierGroup) label() string {
+	return strconv.FormatFloat(g.multiplier, 'g', -1, 64) + "x"
+}
+
+func groupsRecord(groups []multiplierGroup) record {
+	r := make(record, 0, len(groups))
+	for _, g := range groups {
+		r = append(r, field{g.label(), g.types})
+	}
+	return r
+}
+
+func printGroups(groups []multiplierGroup, preposition string) {
+	if len(groups) == 0 {
+		fmt.Println("   - 1x " + preposition + " every type")
+		return
+	}
+	for _, g := range groups {
+		fmt.Printf("   - %v %s: %v\n", g.label(), preposition, strings.Join(g.types, ", "))
+	}
+}
+
+// loadTypeChart returns the type chart, fetching every type from the
+// PokeAPI the first time it is needed.
+func loadTypeChart(ctx context.Context, cfg *Config) (*typeChart, error) {
+	cfg.mu.RLock()
+	chart := cfg.TypeChart
+	cfg.mu.RUnlock()
+	if chart != nil {
+		return chart, nil
+	}
+	list, err := cfg.Client.ListTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	types := make([]pokeapi.Type, 0, len(list.Results))
+	for _, result := range list.Results {
+		t, err := cfg.Client.GetType(ctx, result.Name)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	chart = newTypeChart(types)
+	cfg.mu.Lock()
+	cfg.TypeChart = chart
+	cfg.mu.Unlock()
+	return chart, nil
+}
+
+func commandType(ctx context.Context, cfg *Config, args commandArgs) error {
+	name := args.arg(0)
+	chart, err := loadTypeChart(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if !chart.has(name) {
+		return fmt.Errorf("Unknown type: %s", name)
+	}
+	attacking := chart.group(func(t string) float64 {
+		return chart.multiplier(name, []string{t})
+	})
+	defending := chart.group(func(t string) float64 {
+		return chart.multiplier(t, []string{name})
+	})
+	r := record{
+		{"name", name},
+		{"attacking", groupsRecord(attacking)},
+		{"defending", groupsRecord(defending)},
+	}
+	return render(cfg, []record{r}, func() {
+		fmt.Printf("Type: %v\n", name)
+		fmt.Println("Attacking:")
+		printGroups(attacking, "against")
+		fmt.Println("Defending:")
+		printGroups(defending, "from")
+	})
+}
+
+// commandWeak shows how much damage each type deals to a Pokemon, taking
+// both of a dual type's types into account, and which types its own types
+// hit super effectively.
+func commandWeak(ctx context.Context, cfg *Config, args commandArgs) error {
+	name := args.arg(0)
+	cfg.mu.RLock()
+	if o, err := cfg.Profile.findOwned(name); err == nil {
+		name = o.Pokemon
+	}
+	entry, caught := cfg.Profile.Pokedex[name]
+	cfg.mu.RUnlock()
+	if !caught {
+		pkm, err := cfg.Client.GetPokemon(ctx, name)
+		if err != nil {
+			return err
+		}
+		entry = newPokedexEntry(pkm)
+	}
+	chart, err := loadTypeChart(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	types := entry.Types
+	taken := chart.group(func(t string) float64 {
+		return chart.multiplier(t, types)
+	})
+	superEffective := record{}
+	for _, own := range types {
+		var against []string
+		for _, t := range chart.types {
+			if chart.multiplier(own, []string{t}) > 1 {
+				against = append(against, t)
+			}
+		}
+		superEffective = append(superEffective, field{own, against})
+	}
+	r := record{
+		{"name", entry.Name},
+		{"types", types},
+		{"damage_taken", groupsRecord(taken)},
+		{"super_effective", superEffective},
+	}
+	return render(cfg, []record{r}, func() {
+		fmt.Printf("%v (%v)\n", entry.Name, strings.Join(types, ", "))
+		fmt.Println("Damage taken:")
+		printGroups(taken, "from")
+		fmt.Println("Super effective with:")
+		for _, f := range superEffective {
+			against := f.value.([]string)
+			if len(against) == 0 {
+				fmt.Printf("   - %v against: nothing\n", f.name)
+				continue
+			}
+			fmt.Printf("   - %v against: %v\n", f.name, strings.Join(against, ", "))
+		}
+	})
+}
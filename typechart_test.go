@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/UUest/pokecli/internal/pokeapi"
+)
+
+func testType(t *testing.T, raw string) pokeapi.Type {
+	t.Helper()
+	typ := pokeapi.Type{}
+	if err := json.Unmarshal([]byte(raw), &typ); err != nil {
+		t.Fatal(err)
+	}
+	return typ
+}
+
+func TestEffectiveness(t *testing.T) {
+	electric := testType(t, `{"name": "electric", "damage_relations": {
+		"double_damage_to": [{"name": "flying"}, {"name": "water"}],
+		"half_damage_to": [{"name": "electric"}, {"name": "grass"}],
+		"no_damage_to": [{"name": "ground"}]}}`)
+
+	cases := []struct {
+		defending []string
+		expected  float64
+	}{
+		{defending: []string{"normal"}, expected: 1},
+		{defending: []string{"normal", "flying"}, expected: 2},
+		{defending: []string{"water", "flying"}, expected: 4},
+		{defending: []string{"grass"}, expected: 0.5},
+		{defending: []string{"water", "grass"}, expected: 1},
+		{defending: []string{"electric", "grass"}, expected: 0.25},
+		{defending: []string{"water", "ground"}, expected: 0},
+	}
+
+	for _, c := range cases {
+		if actual := effectiveness(electric, c.defending); actual != c.expected {
+			t.Errorf("electric against %v: expected %v, got %v", c.defending, c.expected, actual)
+		}
+	}
+}
+
+// typeHandler serves a slice of the type chart: electric, ground, flying and
+// water, plus the "unknown" type that has no damage relations.
+func typeHandler(w http.ResponseWriter, r *http.Request) {
+	relations := map[string]string{
+		"electric": `"double_damage_to": [{"name": "flying"}, {"name": "water"}], "half_damage_to": [{"name": "electric"}],
+			"no_damage_to": [{"name": "ground"}], "double_damage_from": [{"name": "ground"}], "half_damage_from": [{"name": "electric"}, {"name": "flying"}]`,
+		"ground": `"double_damage_to": [{"name": "electric"}], "no_damage_to": [{"name": "flying"}],
+			"double_damage_from": [{"name": "water"}], "no_damage_from": [{"name": "electric"}]`,
+		"flying":  `"half_damage_to": [{"name": "electric"}], "double_damage_from": [{"name": "electric"}], "no_damage_from": [{"name": "ground"}]`,
+		"water":   `"double_damage_to": [{"name": "ground"}], "half_damage_to": [{"name": "water"}], "double_damage_from": [{"name": "electric"}], "half_damage_from": [{"name": "water"}]`,
+		"unknown": ``,
+	}
+	switch {
+	case r.URL.Path == "/type":
+		fmt.Fprint(w, `{"results": [{"name": "flying"}, {"name": "ground"}, {"name": "water"}, {"name": "electric"}, {"name": "unknown"}]}`)
+	case strings.HasPrefix(r.URL.Path, "/type/"):
+		name := strings.TrimPrefix(r.URL.Path, "/type/")
+		rel, ok := relations[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"name": %q, "damage_relations": {%s}}`, name, rel)
+	case r.URL.Path == "/pokemon/gyarados":
+		fmt.Fprint(w, `{"name": "gyarados", "types": [{"type": {"name": "water"}}, {"type": {"name": "flying"}}]}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestTypeChart(t *testing.T) {
+	cfg := newTestConfig(t, typeHandler)
+	chart, err := loadTypeChart(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual := strings.Join(chart.types, ","); actual != "flying,ground,water,electric" {
+		t.Errorf("expected the types in PokeAPI order without unknown, got %v", actual)
+	}
+
+	cases := []struct {
+		attacking string
+		defending []string
+		expected  float64
+	}{
+		{attacking: "electric", defending: []string{"water"}, expected: 2},
+		{attacking: "electric", defending: []string{"water", "flying"}, expected: 4},
+		{attacking: "electric", defending: []string{"electric"}, expected: 0.5},
+		{attacking: "electric", defending: []string{"water", "ground"}, expected: 0},
+		{attacking: "water", defending: []string{"flying"}, expected: 1},
+		{attacking: "ground", defending: []string{"electric", "flying"}, expected: 0},
+		{attacking: "unknown", defending: []string{"water"}, expected: 1},
+	}
+	for _, c := range cases {
+		if actual := chart.multiplier(c.attacking, c.defending); actual != c.expected {
+			t.Errorf("%v against %v: expected %v, got %v", c.attacking, c.defending, c.expected, actual)
+		}
+	}
+
+	if again, _ := loadTypeChart(context.Background(), cfg); again != chart {
+		t.Errorf("expected the chart to be loaded once")
+	}
+}
+
+func TestTypeChartGroup(t *testing.T) {
+	cfg := newTestConfig(t, typeHandler)
+	chart, err := loadTypeChart(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gyarados := []string{"water", "flying"}
+	groups := chart.group(func(attacking string) float64 {
+		return chart.multiplier(attacking, gyarados)
+	})
+	var actual []string
+	for _, g := range groups {
+		actual = append(actual, g.label()+" "+strings.Join(g.types, ","))
+	}
+	expected := "4x electric|0.5x water|0x ground"
+	if strings.Join(actual, "|") != expected {
+		t.Errorf("expected %v, got %v", expected, strings.Join(actual, "|"))
+	}
+}
+
+func TestTypeAndWeakCommands(t *testing.T) {
+	cfg := newTestConfig(t, typeHandler)
+	ctx := context.Background()
+
+	if err := runCommand(ctx, cfg, commands["type"], []string{"electric"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := runCommand(ctx, cfg, commands["type"], []string{"unknown"}); err == nil {
+		t.Errorf("expected an error for a type no Pokemon has")
+	}
+	if err := runCommand(ctx, cfg, commands["weak"], []string{"gyarados"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := runCommand(ctx, cfg, commands["weak"], []string{"missingno"}); err == nil {
+		t.Errorf("expected an error for a Pokemon that does not exist")
+	}
+}